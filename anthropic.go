@@ -0,0 +1,567 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// anthropic.go translates chat completion requests into the Anthropic Messages API.
+// refs: https://docs.anthropic.com/en/api/messages
+
+const (
+	anthropicMessagesSuffix  = "/messages"
+	anthropicAPIKeyHeader    = "x-api-key"
+	anthropicDefaultMaxToken = 4096
+	// anthropicMaxTemperature bounds the temperature, which ranges from 0 to 1
+	// instead of 0 to 2.
+	anthropicMaxTemperature = 1
+)
+
+var (
+	ErrAnthropicUnsupportedRole        = errors.New("this message role is not supported by the Anthropic Messages API")
+	ErrAnthropicUnsupportedContentPart = errors.New("this message content part is not supported by the Anthropic Messages API") //nolint:lll
+	ErrAnthropicUnsupportedParameter   = errors.New("this parameter is not supported by the Anthropic Messages API")
+)
+
+type anthropicRequest struct {
+	Model         string               `json:"model"`
+	System        string               `json:"system,omitempty"`
+	Messages      []anthropicMessage   `json:"messages"`
+	MaxTokens     int                  `json:"max_tokens"`
+	StopSequences []string             `json:"stop_sequences,omitempty"`
+	Temperature   float32              `json:"temperature,omitempty"`
+	TopP          float32              `json:"top_p,omitempty"`
+	Stream        bool                 `json:"stream,omitempty"`
+	Tools         []anthropicTool      `json:"tools,omitempty"`
+	ToolChoice    *anthropicToolChoice `json:"tool_choice,omitempty"`
+	Metadata      *anthropicMetadata   `json:"metadata,omitempty"`
+}
+
+type anthropicMessage struct {
+	Role    string                  `json:"role"`
+	Content []anthropicContentBlock `json:"content"`
+}
+
+type anthropicContentBlock struct {
+	Type string `json:"type"`
+	// text blocks
+	Text string `json:"text,omitempty"`
+	// image blocks
+	Source *anthropicImageSource `json:"source,omitempty"`
+	// tool_use blocks
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+	// tool_result blocks
+	ToolUseID string `json:"tool_use_id,omitempty"`
+	Content   string `json:"content,omitempty"`
+	// thinking blocks
+	Thinking string `json:"thinking,omitempty"`
+}
+
+type anthropicImageSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
+type anthropicTool struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	InputSchema any    `json:"input_schema"`
+}
+
+type anthropicToolChoice struct {
+	Type                   string `json:"type"`
+	Name                   string `json:"name,omitempty"`
+	DisableParallelToolUse bool   `json:"disable_parallel_tool_use,omitempty"`
+}
+
+type anthropicMetadata struct {
+	UserID string `json:"user_id,omitempty"`
+}
+
+type anthropicUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+type anthropicResponse struct {
+	ID         string                  `json:"id"`
+	Type       string                  `json:"type"`
+	Role       string                  `json:"role"`
+	Model      string                  `json:"model"`
+	Content    []anthropicContentBlock `json:"content"`
+	StopReason string                  `json:"stop_reason"`
+	Usage      anthropicUsage          `json:"usage"`
+
+	httpHeader
+}
+
+// createAnthropicChatCompletion sends the request to the Anthropic Messages API.
+func (c *Client) createAnthropicChatCompletion(
+	ctx context.Context,
+	request ChatCompletionRequest,
+) (response ChatCompletionResponse, err error) {
+	body, err := newAnthropicRequest(request)
+	if err != nil {
+		return
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(anthropicMessagesSuffix),
+		withBody(body), withHookBody(request))
+	if err != nil {
+		return
+	}
+
+	var anthropicResp anthropicResponse
+	err = c.sendTranslatedRequest(req, &anthropicResp, func() Response {
+		response = anthropicResp.toChatCompletionResponse()
+		return &response
+	})
+	return
+}
+
+// createAnthropicChatCompletionStream streams the request from the Anthropic Messages API,
+// converting its typed events into chat completion chunks.
+func (c *Client) createAnthropicChatCompletionStream(
+	ctx context.Context,
+	request ChatCompletionRequest,
+) (stream *ChatCompletionStream, err error) {
+	body, err := newAnthropicRequest(request)
+	if err != nil {
+		return
+	}
+	body.Stream = true
+
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(anthropicMessagesSuffix),
+		withBody(body), withHookBody(request))
+	if err != nil {
+		return
+	}
+
+	resp, err := sendRequestStream[ChatCompletionStreamResponse](c, req)
+	if err != nil {
+		return
+	}
+	resp.transform = newAnthropicStreamConverter().convert
+	stream = &ChatCompletionStream{
+		streamReader: resp,
+	}
+	return
+}
+
+func newAnthropicRequest(request ChatCompletionRequest) (anthropicRequest, error) {
+	if err := checkAnthropicParameters(request); err != nil {
+		return anthropicRequest{}, err
+	}
+	r := anthropicRequest{
+		Model:         request.Model,
+		MaxTokens:     request.MaxCompletionTokens,
+		StopSequences: request.Stop,
+		Temperature:   request.Temperature,
+		TopP:          request.TopP,
+	}
+	if r.MaxTokens == 0 {
+		r.MaxTokens = request.MaxTokens
+	}
+	if r.MaxTokens == 0 {
+		r.MaxTokens = anthropicDefaultMaxToken
+	}
+	if request.User != "" {
+		r.Metadata = &anthropicMetadata{UserID: request.User}
+	}
+
+	var system []string
+	for _, message := range request.Messages {
+		if message.Role == ChatMessageRoleSystem || message.Role == ChatMessageRoleDeveloper {
+			system = append(system, messageText(message))
+			continue
+		}
+		converted, err := newAnthropicMessage(message)
+		if err != nil {
+			return r, err
+		}
+		// The API rejects messages without content, which assistant messages
+		// of OpenAI conversations may have.
+		if converted.Role == ChatMessageRoleAssistant && len(converted.Content) == 0 {
+			continue
+		}
+		// Anthropic expects alternating roles, consecutive messages of the same role
+		// such as several tool results are merged into a single message.
+		if n := len(r.Messages); n > 0 && r.Messages[n-1].Role == converted.Role {
+			r.Messages[n-1].Content = append(r.Messages[n-1].Content, converted.Content...)
+			continue
+		}
+		r.Messages = append(r.Messages, converted)
+	}
+	r.System = strings.Join(system, "\n\n")
+
+	r.Tools = newAnthropicTools(request)
+	r.ToolChoice = newAnthropicToolChoice(request.ToolChoice, request.ParallelToolCalls)
+	return r, nil
+}
+
+// checkAnthropicParameters rejects the parameters of request that have no
+// equivalent in the Messages API, rather than dropping them.
+func checkAnthropicParameters(request ChatCompletionRequest) error {
+	var parameter string
+	switch {
+	case request.Temperature > anthropicMaxTemperature:
+		parameter = "temperature above 1"
+	case request.N > 1:
+		parameter = "n"
+	case request.Seed != nil:
+		parameter = "seed"
+	case request.LogProbs || request.TopLogProbs > 0:
+		parameter = "logprobs"
+	case request.ResponseFormat != nil && request.ResponseFormat.Type != "" &&
+		request.ResponseFormat.Type != ChatCompletionResponseFormatTypeText:
+		parameter = "response_format " + string(request.ResponseFormat.Type)
+	default:
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrAnthropicUnsupportedParameter, parameter)
+}
+
+func newAnthropicMessage(message ChatCompletionMessage) (anthropicMessage, error) {
+	switch message.Role {
+	case ChatMessageRoleUser:
+		blocks, err := newAnthropicContentBlocks(message)
+		return anthropicMessage{Role: ChatMessageRoleUser, Content: blocks}, err
+	case ChatMessageRoleAssistant:
+		blocks, err := newAnthropicContentBlocks(message)
+		if err != nil {
+			return anthropicMessage{}, err
+		}
+		for _, call := range message.ToolCalls {
+			input := json.RawMessage(call.Function.Arguments)
+			if len(input) == 0 {
+				input = json.RawMessage("{}")
+			}
+			blocks = append(blocks, anthropicContentBlock{
+				Type:  "tool_use",
+				ID:    call.ID,
+				Name:  call.Function.Name,
+				Input: input,
+			})
+		}
+		return anthropicMessage{Role: ChatMessageRoleAssistant, Content: blocks}, nil
+	case ChatMessageRoleTool:
+		return anthropicMessage{
+			Role: ChatMessageRoleUser,
+			Content: []anthropicContentBlock{{
+				Type:      "tool_result",
+				ToolUseID: message.ToolCallID,
+				Content:   messageText(message),
+			}},
+		}, nil
+	default:
+		return anthropicMessage{}, fmt.Errorf("%w: %s", ErrAnthropicUnsupportedRole, message.Role)
+	}
+}
+
+func newAnthropicContentBlocks(message ChatCompletionMessage) ([]anthropicContentBlock, error) {
+	var blocks []anthropicContentBlock
+	if message.Content != "" {
+		blocks = append(blocks, anthropicContentBlock{Type: "text", Text: message.Content})
+	}
+	for _, part := range message.MultiContent {
+		switch part.Type {
+		case ChatMessagePartTypeText:
+			blocks = append(blocks, anthropicContentBlock{Type: "text", Text: part.Text})
+		case ChatMessagePartTypeImageURL:
+			if part.ImageURL == nil {
+				continue
+			}
+			blocks = append(blocks, anthropicContentBlock{
+				Type:   "image",
+				Source: newAnthropicImageSource(part.ImageURL.URL),
+			})
+		case ChatMessagePartTypeInputAudio:
+			fallthrough
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrAnthropicUnsupportedContentPart, part.Type)
+		}
+	}
+	return blocks, nil
+}
+
+// newAnthropicImageSource converts data URLs (data:image/png;base64,...) into base64
+// sources and passes other URLs through.
+func newAnthropicImageSource(url string) *anthropicImageSource {
+	if strings.HasPrefix(url, "data:") {
+		meta, data, found := strings.Cut(strings.TrimPrefix(url, "data:"), ",")
+		if found && strings.HasSuffix(meta, ";base64") {
+			return &anthropicImageSource{
+				Type:      "base64",
+				MediaType: strings.TrimSuffix(meta, ";base64"),
+				Data:      data,
+			}
+		}
+	}
+	return &anthropicImageSource{Type: "url", URL: url}
+}
+
+func newAnthropicTools(request ChatCompletionRequest) []anthropicTool {
+	definitions := make([]FunctionDefinition, 0, len(request.Tools)+len(request.Functions))
+	for _, tool := range request.Tools {
+		if tool.Function != nil {
+			definitions = append(definitions, *tool.Function)
+		}
+	}
+	definitions = append(definitions, request.Functions...)
+
+	var tools []anthropicTool
+	for _, definition := range definitions {
+		schema := definition.Parameters
+		if schema == nil {
+			schema = map[string]any{"type": "object", "properties": map[string]any{}}
+		}
+		tools = append(tools, anthropicTool{
+			Name:        definition.Name,
+			Description: definition.Description,
+			InputSchema: schema,
+		})
+	}
+	return tools
+}
+
+func newAnthropicToolChoice(toolChoice, parallelToolCalls any) *anthropicToolChoice {
+	var choice *anthropicToolChoice
+	switch v := toolChoice.(type) {
+	case string:
+		switch v {
+		case "none":
+			choice = &anthropicToolChoice{Type: "none"}
+		case "required":
+			choice = &anthropicToolChoice{Type: "any"}
+		default:
+			choice = &anthropicToolChoice{Type: "auto"}
+		}
+	case ToolChoice:
+		choice = &anthropicToolChoice{Type: "tool", Name: v.Function.Name}
+	case *ToolChoice:
+		choice = &anthropicToolChoice{Type: "tool", Name: v.Function.Name}
+	}
+
+	if parallel, ok := parallelToolCalls.(bool); ok && !parallel {
+		if choice == nil {
+			choice = &anthropicToolChoice{Type: "auto"}
+		}
+		choice.DisableParallelToolUse = true
+	}
+	return choice
+}
+
+// messageText returns the text of a message, joining the text parts of multi content messages.
+func messageText(message ChatCompletionMessage) string {
+	if len(message.MultiContent) == 0 {
+		return message.Content
+	}
+	var texts []string
+	for _, part := range message.MultiContent {
+		if part.Type == ChatMessagePartTypeText {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+func anthropicFinishReason(stopReason string) FinishReason {
+	switch stopReason {
+	case "end_turn", "stop_sequence", "pause_turn":
+		return FinishReasonStop
+	case "max_tokens":
+		return FinishReasonLength
+	case "tool_use":
+		return FinishReasonToolCalls
+	case "refusal":
+		return FinishReasonContentFilter
+	case "":
+		return ""
+	default:
+		return FinishReason(stopReason)
+	}
+}
+
+func (u anthropicUsage) toUsage() Usage {
+	promptTokens := u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
+	return Usage{
+		PromptTokens:     promptTokens,
+		CompletionTokens: u.OutputTokens,
+		TotalTokens:      promptTokens + u.OutputTokens,
+		PromptTokensDetails: &PromptTokensDetails{
+			CachedTokens: u.CacheReadInputTokens,
+		},
+	}
+}
+
+func (r *anthropicResponse) toChatCompletionResponse() ChatCompletionResponse {
+	message := ChatCompletionMessage{Role: ChatMessageRoleAssistant}
+	var texts, thoughts []string
+	for _, block := range r.Content {
+		switch block.Type {
+		case "text":
+			texts = append(texts, block.Text)
+		case "thinking":
+			thoughts = append(thoughts, block.Thinking)
+		case "tool_use":
+			message.ToolCalls = append(message.ToolCalls, ToolCall{
+				ID:   block.ID,
+				Type: ToolTypeFunction,
+				Function: FunctionCall{
+					Name:      block.Name,
+					Arguments: string(block.Input),
+				},
+			})
+		}
+	}
+	message.Content = strings.Join(texts, "")
+	message.ReasoningContent = strings.Join(thoughts, "")
+
+	return ChatCompletionResponse{
+		ID:      r.ID,
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   r.Model,
+		Choices: []ChatCompletionChoice{{
+			Index:        0,
+			Message:      message,
+			FinishReason: anthropicFinishReason(r.StopReason),
+		}},
+		Usage:      r.Usage.toUsage(),
+		httpHeader: r.httpHeader,
+	}
+}
+
+// anthropicStreamEvent is the union of the data payloads of Anthropic stream events.
+type anthropicStreamEvent struct {
+	Type         string                 `json:"type"`
+	Index        int                    `json:"index"`
+	Message      *anthropicResponse     `json:"message,omitempty"`
+	ContentBlock *anthropicContentBlock `json:"content_block,omitempty"`
+	Delta        struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		PartialJSON string `json:"partial_json"`
+		Thinking    string `json:"thinking"`
+		StopReason  string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage *anthropicUsage `json:"usage,omitempty"`
+	Error *APIError       `json:"error,omitempty"`
+}
+
+// anthropicStreamConverter keeps the state needed to map Anthropic stream events
+// into chat completion chunks.
+type anthropicStreamConverter struct {
+	id      string
+	model   string
+	created int64
+	usage   anthropicUsage
+	// toolCalls maps content block indexes to tool call indexes.
+	toolCalls map[int]int
+}
+
+func newAnthropicStreamConverter() *anthropicStreamConverter {
+	return &anthropicStreamConverter{
+		created:   time.Now().Unix(),
+		toolCalls: make(map[int]int),
+	}
+}
+
+// convert maps a single Anthropic event into a chat completion chunk. Events without
+// an equivalent chunk are skipped by returning nil, message_stop ends the stream.
+func (s *anthropicStreamConverter) convert(data []byte) ([]byte, error) {
+	var event anthropicStreamEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, err
+	}
+
+	var choice ChatCompletionStreamChoice
+	var usage *Usage
+	switch event.Type {
+	case "message_start":
+		if event.Message != nil {
+			s.id = event.Message.ID
+			s.model = event.Message.Model
+			s.usage = event.Message.Usage
+		}
+		choice.Delta.Role = ChatMessageRoleAssistant
+	case "content_block_start":
+		if event.ContentBlock == nil || event.ContentBlock.Type != "tool_use" {
+			return nil, nil
+		}
+		index := len(s.toolCalls)
+		s.toolCalls[event.Index] = index
+		choice.Delta.ToolCalls = []ToolCall{{
+			Index:    &index,
+			ID:       event.ContentBlock.ID,
+			Type:     ToolTypeFunction,
+			Function: FunctionCall{Name: event.ContentBlock.Name},
+		}}
+	case "content_block_delta":
+		if !s.convertDelta(&event, &choice.Delta) {
+			return nil, nil
+		}
+	case "message_delta":
+		if event.Usage != nil {
+			s.usage.OutputTokens = event.Usage.OutputTokens
+		}
+		choice.FinishReason = anthropicFinishReason(event.Delta.StopReason)
+		u := s.usage.toUsage()
+		usage = &u
+	case "message_stop":
+		return nil, io.EOF
+	case "error":
+		if event.Error == nil {
+			return nil, fmt.Errorf("error, %s", data)
+		}
+		return nil, fmt.Errorf("error, %w", event.Error)
+	default:
+		// ping, content_block_stop and unknown events have no chunk equivalent.
+		return nil, nil
+	}
+
+	return json.Marshal(ChatCompletionStreamResponse{
+		ID:      s.id,
+		Object:  "chat.completion.chunk",
+		Created: s.created,
+		Model:   s.model,
+		Choices: []ChatCompletionStreamChoice{choice},
+		Usage:   usage,
+	})
+}
+
+func (s *anthropicStreamConverter) convertDelta(
+	event *anthropicStreamEvent,
+	delta *ChatCompletionStreamChoiceDelta,
+) bool {
+	switch event.Delta.Type {
+	case "text_delta":
+		delta.Content = event.Delta.Text
+	case "thinking_delta":
+		delta.ReasoningContent = event.Delta.Thinking
+	case "input_json_delta":
+		index, ok := s.toolCalls[event.Index]
+		if !ok {
+			return false
+		}
+		delta.ToolCalls = []ToolCall{{
+			Index:    &index,
+			Function: FunctionCall{Arguments: event.Delta.PartialJSON},
+		}}
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,357 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/ibanyu/go-openai"
+	"github.com/ibanyu/go-openai/internal/test"
+	"github.com/ibanyu/go-openai/internal/test/checks"
+)
+
+func setupAnthropicTestServer() (client *openai.Client, server *test.ServerTest, teardown func()) {
+	return setupAnthropicTestServerWithConfig(func(*openai.ClientConfig) {})
+}
+
+// setupAnthropicTestServerWithConfig is setupAnthropicTestServer with a
+// client configured by configure.
+func setupAnthropicTestServerWithConfig(
+	configure func(config *openai.ClientConfig),
+) (client *openai.Client, server *test.ServerTest, teardown func()) {
+	server = test.NewTestServer()
+	ts := server.OpenAITestServer()
+	ts.Start()
+	teardown = ts.Close
+	config := openai.DefaultAnthropicConfig(test.GetTestToken(), ts.URL+"/v1")
+	configure(&config)
+	client = openai.NewClientWithConfig(config)
+	return
+}
+
+func TestAnthropicChatCompletion(t *testing.T) {
+	client, server, teardown := setupAnthropicTestServer()
+	defer teardown()
+
+	var body map[string]any
+	server.RegisterHandler("/v1/messages", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") != test.GetTestToken() || r.Header.Get("Authorization") != "" {
+			t.Errorf("unexpected auth headers: %v", r.Header)
+		}
+		if r.Header.Get("anthropic-version") != openai.AnthropicAPIVersion || r.URL.RawQuery != "" {
+			t.Errorf("unexpected version: %v %q", r.Header, r.URL.RawQuery)
+		}
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&body), "decode request error")
+		fmt.Fprint(w, `{
+			"id": "msg_1", "type": "message", "role": "assistant", "model": "claude-sonnet-4",
+			"content": [
+				{"type": "thinking", "thinking": "hmm"},
+				{"type": "text", "text": "Let me check."},
+				{"type": "tool_use", "id": "toolu_1", "name": "get_weather", "input": {"city": "Paris"}}
+			],
+			"stop_reason": "tool_use",
+			"usage": {"input_tokens": 10, "output_tokens": 5, "cache_read_input_tokens": 2}
+		}`)
+	})
+
+	resp, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model:      "claude-sonnet-4",
+		MaxTokens:  100,
+		Stop:       []string{"END"},
+		ToolChoice: "required",
+		Tools: []openai.Tool{{
+			Type:     openai.ToolTypeFunction,
+			Function: &openai.FunctionDefinition{Name: "get_weather", Parameters: json.RawMessage(`{"type":"object"}`)},
+		}},
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: "Be brief."},
+			{Role: openai.ChatMessageRoleUser, MultiContent: []openai.ChatMessagePart{
+				{Type: openai.ChatMessagePartTypeText, Text: "What is this?"},
+				{
+					Type:     openai.ChatMessagePartTypeImageURL,
+					ImageURL: &openai.ChatMessageImageURL{URL: "data:image/png;base64,AAAA"},
+				},
+			}},
+			{Role: openai.ChatMessageRoleAssistant, ToolCalls: []openai.ToolCall{
+				{ID: "toolu_0", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "a", Arguments: `{}`}},
+				{ID: "toolu_9", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "b"}},
+			}},
+			{Role: openai.ChatMessageRoleTool, ToolCallID: "toolu_0", Content: "1"},
+			{Role: openai.ChatMessageRoleTool, ToolCallID: "toolu_9", Content: "2"},
+		},
+	})
+	checks.NoError(t, err, "CreateChatCompletion error")
+
+	if body["system"] != "Be brief." || body["max_tokens"] != float64(100) {
+		t.Errorf("unexpected request: %v", body)
+	}
+	if choice, _ := body["tool_choice"].(map[string]any); choice["type"] != "any" {
+		t.Errorf("unexpected tool_choice: %v", body["tool_choice"])
+	}
+	messages, _ := body["messages"].([]any)
+	if len(messages) != 3 {
+		t.Fatalf("expected tool results to be merged into 3 messages, got %v", messages)
+	}
+	results, _ := messages[2].(map[string]any)["content"].([]any)
+	if len(results) != 2 || messages[2].(map[string]any)["role"] != "user" {
+		t.Errorf("unexpected tool results: %v", messages[2])
+	}
+	image, _ := messages[0].(map[string]any)["content"].([]any)[1].(map[string]any)["source"].(map[string]any)
+	if image["type"] != "base64" || image["media_type"] != "image/png" || image["data"] != "AAAA" {
+		t.Errorf("unexpected image source: %v", image)
+	}
+
+	choice := resp.Choices[0]
+	if choice.FinishReason != openai.FinishReasonToolCalls || choice.Message.Content != "Let me check." ||
+		choice.Message.ReasoningContent != "hmm" {
+		t.Errorf("unexpected choice: %+v", choice)
+	}
+	if len(choice.Message.ToolCalls) != 1 || choice.Message.ToolCalls[0].Function.Arguments != `{"city": "Paris"}` {
+		t.Errorf("unexpected tool calls: %+v", choice.Message.ToolCalls)
+	}
+	if resp.Usage.PromptTokens != 12 || resp.Usage.CompletionTokens != 5 || resp.Usage.TotalTokens != 17 {
+		t.Errorf("unexpected usage: %+v", resp.Usage)
+	}
+}
+
+func TestAnthropicHooksSeeOpenAITypes(t *testing.T) {
+	var (
+		estimated    any
+		seenRequests []any
+		seenResponse openai.Response
+	)
+	client, server, teardown := setupAnthropicTestServerWithConfig(func(config *openai.ClientConfig) {
+		config.RateLimiter = openai.NewRateLimiter(openai.RateLimiterConfig{
+			TokenEstimator: func(request any) int {
+				estimated = request
+				return 1
+			},
+		})
+		config.RequestHooks = []openai.RequestHook{func(_ context.Context, _ *http.Request, body any) error {
+			seenRequests = append(seenRequests, body)
+			return nil
+		}}
+		config.ResponseHooks = []openai.ResponseHook{
+			func(_ context.Context, _ *http.Response, v openai.Response, _ error) {
+				seenResponse = v
+			},
+		}
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/messages", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"id": "msg_1", "model": "claude-sonnet-4", "content": [{"type": "text", "text": "Hi"}],
+			"stop_reason": "end_turn", "usage": {"input_tokens": 1, "output_tokens": 1}}`)
+	})
+
+	request := openai.ChatCompletionRequest{
+		Model:    "claude-sonnet-4",
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello"}},
+	}
+	_, err := client.CreateChatCompletion(context.Background(), request)
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if _, ok := estimated.(openai.ChatCompletionRequest); !ok {
+		t.Errorf("unexpected rate limiter request: %T", estimated)
+	}
+	if response, ok := seenResponse.(*openai.ChatCompletionResponse); !ok || response.Choices[0].Message.Content != "Hi" {
+		t.Errorf("unexpected hook response: %#v", seenResponse)
+	}
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), request)
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	stream.Close()
+	if len(seenRequests) != 2 {
+		t.Fatalf("unexpected hook requests: %v", seenRequests)
+	}
+	for _, body := range seenRequests {
+		if r, ok := body.(openai.ChatCompletionRequest); !ok || r.Model != request.Model {
+			t.Errorf("unexpected hook request: %#v", body)
+		}
+	}
+}
+
+func TestAnthropicChatCompletionError(t *testing.T) {
+	client, server, teardown := setupAnthropicTestServer()
+	defer teardown()
+
+	server.RegisterHandler("/v1/messages", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"type":"error","error":{"type":"rate_limit_error","message":"slow down"}}`)
+	})
+
+	_, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model:    "claude-sonnet-4",
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello!"}},
+	})
+	var apiErr *openai.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected APIError, got %v", err)
+	}
+	if apiErr.Type != "rate_limit_error" || apiErr.Message != "slow down" || apiErr.HTTPStatusCode != 429 {
+		t.Errorf("unexpected error: %+v", apiErr)
+	}
+}
+
+func TestAnthropicUnsupportedRole(t *testing.T) {
+	client, _, teardown := setupAnthropicTestServer()
+	defer teardown()
+
+	_, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model:    "claude-sonnet-4",
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleFunction, Content: "Hello!"}},
+	})
+	checks.ErrorIs(t, err, openai.ErrAnthropicUnsupportedRole, "expected ErrAnthropicUnsupportedRole")
+}
+
+func TestAnthropicUnsupportedParameters(t *testing.T) {
+	client, _, teardown := setupAnthropicTestServer()
+	defer teardown()
+
+	seed := 1
+	tests := map[string]func(request *openai.ChatCompletionRequest){
+		"temperature": func(request *openai.ChatCompletionRequest) { request.Temperature = 1.5 },
+		"n":           func(request *openai.ChatCompletionRequest) { request.N = 2 },
+		"seed":        func(request *openai.ChatCompletionRequest) { request.Seed = &seed },
+		"logprobs":    func(request *openai.ChatCompletionRequest) { request.LogProbs = true },
+		"response_format": func(request *openai.ChatCompletionRequest) {
+			request.ResponseFormat = &openai.ChatCompletionResponseFormat{
+				Type: openai.ChatCompletionResponseFormatTypeJSONObject,
+			}
+		},
+	}
+	for name, set := range tests {
+		request := openai.ChatCompletionRequest{
+			Model:    "claude-sonnet-4",
+			Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello!"}},
+		}
+		set(&request)
+		_, err := client.CreateChatCompletion(context.Background(), request)
+		checks.ErrorIs(t, err, openai.ErrAnthropicUnsupportedParameter, name)
+	}
+}
+
+func TestAnthropicSkipsEmptyAssistantMessages(t *testing.T) {
+	client, server, teardown := setupAnthropicTestServer()
+	defer teardown()
+
+	var body struct {
+		Messages []struct {
+			Role    string            `json:"role"`
+			Content []json.RawMessage `json:"content"`
+		} `json:"messages"`
+	}
+	server.RegisterHandler("/v1/messages", func(w http.ResponseWriter, r *http.Request) {
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&body), "decode request error")
+		fmt.Fprint(w, `{"id":"msg_1","role":"assistant","content":[{"type":"text","text":"Hi"}],"stop_reason":"end_turn"}`)
+	})
+
+	_, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model: "claude-sonnet-4",
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleUser, Content: "Hello"},
+			{Role: openai.ChatMessageRoleAssistant},
+			{Role: openai.ChatMessageRoleUser, Content: "Anyone there?"},
+		},
+	})
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if len(body.Messages) != 1 || body.Messages[0].Role != "user" || len(body.Messages[0].Content) != 2 {
+		t.Errorf("expected the empty assistant message to be skipped, got %+v", body.Messages)
+	}
+}
+
+func TestAnthropicChatCompletionStream(t *testing.T) {
+	client, server, teardown := setupAnthropicTestServer()
+	defer teardown()
+
+	server.RegisterHandler("/v1/messages", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&body), "decode request error")
+		if body["stream"] != true {
+			t.Errorf("expected stream to be set: %v", body)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		events := []string{
+			`{"type":"message_start","message":{"id":"msg_1","model":"claude","usage":{"input_tokens":7}}}`,
+			`{"type":"ping"}`,
+			`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hi"}}`,
+			`{"type":"content_block_stop","index":0}`,
+			`{"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_1","name":"f"}}`,
+			`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"a\":"}}`,
+			`{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":3}}`,
+			`{"type":"message_stop"}`,
+		}
+		for _, event := range events {
+			var typed struct {
+				Type string `json:"type"`
+			}
+			_ = json.Unmarshal([]byte(event), &typed)
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", typed.Type, event)
+		}
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{
+		Model:    "claude",
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello!"}},
+	})
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+
+	var chunks []openai.ChatCompletionStreamResponse
+	for {
+		chunk, recvErr := stream.Recv()
+		if errors.Is(recvErr, io.EOF) {
+			break
+		}
+		checks.NoErrorF(t, recvErr, "stream.Recv error")
+		chunks = append(chunks, chunk)
+	}
+
+	if len(chunks) != 5 {
+		t.Fatalf("expected 5 chunks, got %d: %+v", len(chunks), chunks)
+	}
+	if chunks[0].ID != "msg_1" || chunks[0].Choices[0].Delta.Role != openai.ChatMessageRoleAssistant {
+		t.Errorf("unexpected first chunk: %+v", chunks[0])
+	}
+	if chunks[1].Choices[0].Delta.Content != "Hi" {
+		t.Errorf("unexpected text chunk: %+v", chunks[1])
+	}
+	toolCall := chunks[2].Choices[0].Delta.ToolCalls[0]
+	if *toolCall.Index != 0 || toolCall.ID != "toolu_1" || toolCall.Function.Name != "f" {
+		t.Errorf("unexpected tool call chunk: %+v", toolCall)
+	}
+	if chunks[3].Choices[0].Delta.ToolCalls[0].Function.Arguments != `{"a":` {
+		t.Errorf("unexpected arguments chunk: %+v", chunks[3])
+	}
+	last := chunks[4]
+	if last.Choices[0].FinishReason != openai.FinishReasonToolCalls || last.Usage == nil || last.Usage.TotalTokens != 10 {
+		t.Errorf("unexpected last chunk: %+v", last)
+	}
+}
+
+func TestAnthropicChatCompletionStreamError(t *testing.T) {
+	client, server, teardown := setupAnthropicTestServer()
+	defer teardown()
+
+	server.RegisterHandler("/v1/messages", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: error\n")
+		fmt.Fprint(w, `data: {"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`+"\n\n")
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{
+		Model:    "claude",
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello!"}},
+	})
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+
+	_, err = stream.Recv()
+	var apiErr *openai.APIError
+	if !errors.As(err, &apiErr) || apiErr.Type != "overloaded_error" {
+		t.Fatalf("expected overloaded APIError, got %v", err)
+	}
+}
@@ -444,6 +444,8 @@ type LogProb struct {
 type LogProbs struct {
 	// Content is a list of message content tokens with log probability information.
 	Content []LogProb `json:"content"`
+	// Refusal is a list of message refusal tokens with log probability information.
+	Refusal []LogProb `json:"refusal,omitempty"`
 }
 
 type FinishReason string
@@ -552,15 +554,27 @@ func (c *Client) CreateChatCompletion(
 	}
 
 	urlSuffix := chatCompletionsSuffix
-	if !checkEndpointSupportsModel(urlSuffix, request.Model) {
+	if !c.modelRegistry().supportsEndpoint(urlSuffix, request.Model) {
 		err = ErrChatCompletionInvalidModel
 		return
 	}
 
-	reasoningValidator := NewReasoningValidator()
+	request = c.normalizeChatCompletionRequest(request)
+	reasoningValidator := NewReasoningValidatorWithRegistry(c.modelRegistry())
 	if err = reasoningValidator.Validate(request); err != nil {
 		return
 	}
+	if err = c.applyStrictSchemaMode(&request); err != nil {
+		return
+	}
+
+	switch c.config.APIType {
+	case APITypeAnthropic:
+		return c.createAnthropicChatCompletion(ctx, request)
+	case APITypeOllama:
+		return c.createOllamaChatCompletion(ctx, request)
+	case APITypeOpenAI, APITypeAzure, APITypeAzureAD, APITypeCloudflareAzure:
+	}
 
 	req, err := c.newRequest(
 		ctx,
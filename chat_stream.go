@@ -223,16 +223,28 @@ func (c *Client) CreateChatCompletionStream(
 	request ChatCompletionRequest,
 ) (stream *ChatCompletionStream, err error) {
 	urlSuffix := chatCompletionsSuffix
-	if !checkEndpointSupportsModel(urlSuffix, request.Model) {
+	if !c.modelRegistry().supportsEndpoint(urlSuffix, request.Model) {
 		err = ErrChatCompletionInvalidModel
 		return
 	}
 
 	request.Stream = true
-	reasoningValidator := NewReasoningValidator()
+	request = c.normalizeChatCompletionRequest(request)
+	reasoningValidator := NewReasoningValidatorWithRegistry(c.modelRegistry())
 	if err = reasoningValidator.Validate(request); err != nil {
 		return
 	}
+	if err = c.applyStrictSchemaMode(&request); err != nil {
+		return
+	}
+
+	switch c.config.APIType {
+	case APITypeAnthropic:
+		return c.createAnthropicChatCompletionStream(ctx, request)
+	case APITypeOllama:
+		return c.createOllamaChatCompletionStream(ctx, request)
+	case APITypeOpenAI, APITypeAzure, APITypeAzureAD, APITypeCloudflareAzure:
+	}
 
 	req, err := c.newRequest(
 		ctx,
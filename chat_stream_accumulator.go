@@ -0,0 +1,213 @@
+package openai
+
+import "sort"
+
+// ChatCompletionAccumulator rebuilds a ChatCompletionResponse from the chunks
+// of a chat completion stream. It merges content, reasoning content, refusals,
+// tool calls, logprobs and extension fields of every choice, and keeps the
+// finish reason and the usage statistics of the final chunk.
+//
+// Tool call arguments are concatenated as they arrive, so the result may hold
+// partial JSON if the stream ended early.
+type ChatCompletionAccumulator struct {
+	response ChatCompletionResponse
+	choices  map[int]*ChatCompletionChoice
+}
+
+// NewChatCompletionAccumulator returns an empty ChatCompletionAccumulator.
+func NewChatCompletionAccumulator() *ChatCompletionAccumulator {
+	return &ChatCompletionAccumulator{
+		choices: make(map[int]*ChatCompletionChoice),
+	}
+}
+
+// Add merges a stream chunk into the accumulated response.
+func (a *ChatCompletionAccumulator) Add(chunk ChatCompletionStreamResponse) {
+	if chunk.ID != "" {
+		a.response.ID = chunk.ID
+	}
+	if chunk.Model != "" {
+		a.response.Model = chunk.Model
+	}
+	if chunk.Created != 0 {
+		a.response.Created = chunk.Created
+	}
+	if chunk.SystemFingerprint != "" {
+		a.response.SystemFingerprint = chunk.SystemFingerprint
+	}
+	if chunk.Usage != nil {
+		a.response.Usage = *chunk.Usage
+	}
+	a.response.PromptFilterResults = append(a.response.PromptFilterResults, chunk.PromptFilterResults...)
+	mergeExtensions(&a.response.RawExtensions, chunk.RawExtensions, false)
+
+	for _, streamChoice := range chunk.Choices {
+		a.addChoice(streamChoice)
+	}
+}
+
+// Response returns the response accumulated so far, with choices ordered by index.
+func (a *ChatCompletionAccumulator) Response() ChatCompletionResponse {
+	response := a.response
+	response.Object = "chat.completion"
+	response.Choices = make([]ChatCompletionChoice, 0, len(a.choices))
+	for _, choice := range a.choices {
+		c := *choice
+		// Index is only set on tool calls of chunk objects.
+		c.Message.ToolCalls = make([]ToolCall, len(choice.Message.ToolCalls))
+		for i, toolCall := range choice.Message.ToolCalls {
+			toolCall.Index = nil
+			c.Message.ToolCalls[i] = toolCall
+		}
+		if len(c.Message.ToolCalls) == 0 {
+			c.Message.ToolCalls = nil
+		}
+		response.Choices = append(response.Choices, c)
+	}
+	sort.Slice(response.Choices, func(i, j int) bool {
+		return response.Choices[i].Index < response.Choices[j].Index
+	})
+	return response
+}
+
+func (a *ChatCompletionAccumulator) addChoice(streamChoice ChatCompletionStreamChoice) {
+	choice, ok := a.choices[streamChoice.Index]
+	if !ok {
+		choice = &ChatCompletionChoice{
+			Index:   streamChoice.Index,
+			Message: ChatCompletionMessage{Role: ChatMessageRoleAssistant},
+		}
+		a.choices[streamChoice.Index] = choice
+	}
+
+	delta := streamChoice.Delta
+	message := &choice.Message
+	if delta.Role != "" {
+		message.Role = delta.Role
+	}
+	message.Content += delta.Content
+	message.ReasoningContent += delta.ReasoningContent
+	message.Refusal += delta.Refusal
+	if delta.FunctionCall != nil {
+		if message.FunctionCall == nil {
+			message.FunctionCall = &FunctionCall{}
+		}
+		mergeFunctionCall(message.FunctionCall, *delta.FunctionCall)
+	}
+	for _, toolCall := range delta.ToolCalls {
+		message.ToolCalls = mergeToolCall(message.ToolCalls, toolCall)
+	}
+	mergeExtensions(&message.RawExtensions, delta.RawExtensions, true)
+
+	if streamChoice.FinishReason != "" {
+		choice.FinishReason = streamChoice.FinishReason
+	}
+	if streamChoice.ContentFilterResults != (ContentFilterResults{}) {
+		choice.ContentFilterResults = streamChoice.ContentFilterResults
+	}
+	if streamChoice.Logprobs != nil {
+		if choice.LogProbs == nil {
+			choice.LogProbs = &LogProbs{}
+		}
+		choice.LogProbs.Content = appendLogProbs(choice.LogProbs.Content, streamChoice.Logprobs.Content)
+		choice.LogProbs.Refusal = appendLogProbs(choice.LogProbs.Refusal, streamChoice.Logprobs.Refusal)
+	}
+	mergeExtensions(&choice.RawExtensions, streamChoice.RawExtensions, false)
+}
+
+// mergeToolCall merges a tool call delta into calls. Deltas are matched by
+// index; providers that omit the index are matched by ID, falling back to the
+// most recent call.
+func mergeToolCall(calls []ToolCall, delta ToolCall) []ToolCall {
+	target := -1
+	switch {
+	case delta.Index != nil:
+		for i := range calls {
+			if calls[i].Index != nil && *calls[i].Index == *delta.Index {
+				target = i
+				break
+			}
+		}
+	case delta.ID != "":
+		for i := range calls {
+			if calls[i].ID == delta.ID {
+				target = i
+				break
+			}
+		}
+	case len(calls) > 0:
+		target = len(calls) - 1
+	}
+
+	if target < 0 {
+		calls = append(calls, ToolCall{Index: delta.Index, Type: ToolTypeFunction})
+		target = len(calls) - 1
+	}
+	call := &calls[target]
+	if delta.ID != "" {
+		call.ID = delta.ID
+	}
+	if delta.Type != "" {
+		call.Type = delta.Type
+	}
+	mergeFunctionCall(&call.Function, delta.Function)
+	mergeExtensions(&call.RawExtensions, delta.RawExtensions, false)
+	return calls
+}
+
+func mergeFunctionCall(call *FunctionCall, delta FunctionCall) {
+	if delta.Name != "" {
+		call.Name = delta.Name
+	}
+	call.Arguments += delta.Arguments
+	mergeExtensions(&call.RawExtensions, delta.RawExtensions, true)
+}
+
+func appendLogProbs(dst []LogProb, src []ChatCompletionTokenLogprob) []LogProb {
+	for _, token := range src {
+		logProb := LogProb{
+			Token:       token.Token,
+			LogProb:     token.Logprob,
+			Bytes:       logprobBytes(token.Bytes),
+			TopLogProbs: make([]TopLogProbs, 0, len(token.TopLogprobs)),
+		}
+		for _, top := range token.TopLogprobs {
+			logProb.TopLogProbs = append(logProb.TopLogProbs, TopLogProbs{
+				Token:   top.Token,
+				LogProb: top.Logprob,
+				Bytes:   logprobBytes(top.Bytes),
+			})
+		}
+		dst = append(dst, logProb)
+	}
+	return dst
+}
+
+func logprobBytes(values []int64) []byte {
+	if values == nil {
+		return nil
+	}
+	b := make([]byte, len(values))
+	for i, v := range values {
+		b[i] = byte(v)
+	}
+	return b
+}
+
+// mergeExtensions copies extension fields from src into dst. When concat is
+// set, string values are appended to the existing ones, as happens for
+// provider-specific fields of streamed deltas; otherwise the last value wins.
+func mergeExtensions(dst *RawExtensions, src RawExtensions, concat bool) {
+	for key, value := range src.Extensions {
+		if concat {
+			if s, ok := value.(string); ok {
+				if prev, exists := dst.GetExtension(key); exists {
+					if prevString, isString := prev.(string); isString {
+						value = prevString + s
+					}
+				}
+			}
+		}
+		dst.SetExtension(key, value)
+	}
+}
@@ -0,0 +1,114 @@
+package openai_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/ibanyu/go-openai"
+	"github.com/ibanyu/go-openai/internal/test/checks"
+)
+
+func TestChatCompletionAccumulator(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		chunks := []string{
+			`{"id":"1","created":10,"model":"gpt-4o","choices":[` +
+				`{"index":1,"delta":{"role":"assistant","content":"B"}},` +
+				`{"index":0,"delta":{"role":"assistant","content":"Hel","provider_note":"a"},` +
+				`"logprobs":{"content":[{"token":"Hel","bytes":[72,101,108],"logprob":-0.1,"top_logprobs":[]}]}}]}`,
+			`{"id":"1","choices":[{"index":0,"delta":{"content":"lo","provider_note":"b"},` +
+				`"logprobs":{"content":[{"token":"lo","logprob":-0.2,"top_logprobs":[{"token":"lo","logprob":-0.2}]}]}}]}`,
+			`{"id":"1","choices":[{"index":1,"delta":{"tool_calls":[` +
+				`{"index":0,"id":"call_1","type":"function","function":{"name":"f","arguments":""}}]}}]}`,
+			`{"id":"1","choices":[{"index":1,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"a\":"}}]}}]}`,
+			`{"id":"1","choices":[{"index":1,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"1}"}}]}}]}`,
+			`{"id":"1","choices":[{"index":0,"delta":{},"finish_reason":"stop"},` +
+				`{"index":1,"delta":{},"finish_reason":"tool_calls"}]}`,
+			`{"id":"1","choices":[],"usage":{"prompt_tokens":5,"completion_tokens":7,"total_tokens":12}}`,
+		}
+		for _, chunk := range chunks {
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{
+		Model:    openai.GPT4o,
+		N:        2,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello!"}},
+	})
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+
+	accumulator := openai.NewChatCompletionAccumulator()
+	for {
+		chunk, recvErr := stream.Recv()
+		if errors.Is(recvErr, io.EOF) {
+			break
+		}
+		checks.NoErrorF(t, recvErr, "stream.Recv error")
+		accumulator.Add(chunk)
+	}
+	resp := accumulator.Response()
+
+	if resp.ID != "1" || resp.Model != "gpt-4o" || resp.Created != 10 || resp.Usage.TotalTokens != 12 {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+	if len(resp.Choices) != 2 {
+		t.Fatalf("expected 2 choices, got %d", len(resp.Choices))
+	}
+
+	first := resp.Choices[0]
+	if first.Index != 0 || first.Message.Content != "Hello" || first.FinishReason != openai.FinishReasonStop {
+		t.Errorf("unexpected first choice: %+v", first)
+	}
+	if note, _ := first.Message.GetExtension("provider_note"); note != "ab" {
+		t.Errorf("expected extension to be concatenated, got %v", note)
+	}
+	if first.LogProbs == nil || len(first.LogProbs.Content) != 2 || string(first.LogProbs.Content[0].Bytes) != "Hel" ||
+		first.LogProbs.Content[1].TopLogProbs[0].LogProb != -0.2 {
+		t.Errorf("unexpected logprobs: %+v", first.LogProbs)
+	}
+
+	second := resp.Choices[1]
+	if second.Message.Content != "B" || second.FinishReason != openai.FinishReasonToolCalls {
+		t.Errorf("unexpected second choice: %+v", second)
+	}
+	if len(second.Message.ToolCalls) != 1 {
+		t.Fatalf("expected 1 tool call, got %+v", second.Message.ToolCalls)
+	}
+	toolCall := second.Message.ToolCalls[0]
+	if toolCall.Index != nil || toolCall.ID != "call_1" || toolCall.Function.Name != "f" ||
+		toolCall.Function.Arguments != `{"a":1}` {
+		t.Errorf("unexpected tool call: %+v", toolCall)
+	}
+}
+
+func TestChatCompletionAccumulatorWithoutToolCallIndex(t *testing.T) {
+	accumulator := openai.NewChatCompletionAccumulator()
+	deltas := []openai.ToolCall{
+		{ID: "a", Function: openai.FunctionCall{Name: "f", Arguments: "{"}},
+		{Function: openai.FunctionCall{Arguments: "}"}},
+		{ID: "b", Function: openai.FunctionCall{Name: "g", Arguments: "[]"}},
+	}
+	for _, delta := range deltas {
+		accumulator.Add(openai.ChatCompletionStreamResponse{Choices: []openai.ChatCompletionStreamChoice{
+			{Delta: openai.ChatCompletionStreamChoiceDelta{ToolCalls: []openai.ToolCall{delta}}},
+		}})
+	}
+
+	toolCalls := accumulator.Response().Choices[0].Message.ToolCalls
+	if len(toolCalls) != 2 || toolCalls[0].Function.Arguments != "{}" || toolCalls[1].Function.Name != "g" {
+		t.Errorf("unexpected tool calls: %+v", toolCalls)
+	}
+	if toolCalls[0].Type != openai.ToolTypeFunction {
+		t.Errorf("expected default tool type, got %q", toolCalls[0].Type)
+	}
+}
@@ -0,0 +1,154 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+
+	"github.com/ibanyu/go-openai/jsonschema"
+)
+
+const defaultStructuredOutputName = "response"
+
+var ErrStructuredOutputNoChoices = errors.New("structured output response has no choices")
+
+var structuredOutputNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// RefusalError is returned by CreateChatCompletionStructured when the model
+// refuses to answer.
+type RefusalError struct {
+	Refusal string
+}
+
+func (e *RefusalError) Error() string {
+	return fmt.Sprintf("model refused to answer: %s", e.Refusal)
+}
+
+// TruncatedOutputError is returned by CreateChatCompletionStructured when the
+// reply was cut off by the token limit before the JSON was complete.
+type TruncatedOutputError struct {
+	Content string
+}
+
+func (e *TruncatedOutputError) Error() string {
+	return "structured output was truncated by the token limit"
+}
+
+// StructuredOutputValidationError is returned by CreateChatCompletionStructured
+// when the reply does not match the schema of the requested type.
+type StructuredOutputValidationError struct {
+	Content string
+	Err     error
+}
+
+func (e *StructuredOutputValidationError) Error() string {
+	return fmt.Sprintf("structured output does not match the schema: %v", e.Err)
+}
+
+func (e *StructuredOutputValidationError) Unwrap() error {
+	return e.Err
+}
+
+// StructuredOutputOptions configures CreateChatCompletionStructured.
+type StructuredOutputOptions struct {
+	// Name is the name of the response format schema. Defaults to the name of
+	// the requested type, or "response" when it is not a valid schema name.
+	Name string
+	// Description describes the response format to the model.
+	Description string
+	// MaxRetries is the number of times the model is asked to correct a reply
+	// that does not match the schema. Defaults to 0.
+	MaxRetries int
+}
+
+// CreateChatCompletionStructured requests a chat completion whose reply is a
+// JSON value of type T. The strict response format schema is generated from T
+// with the jsonschema package, optional fields becoming required fields that
+// accept null, and the reply is validated against it and decoded into the
+// returned value. Refusals and truncated replies are reported as *RefusalError
+// and *TruncatedOutputError.
+func CreateChatCompletionStructured[T any](
+	ctx context.Context,
+	client *Client,
+	request ChatCompletionRequest,
+	options StructuredOutputOptions,
+) (result T, response ChatCompletionResponse, err error) {
+	generated, err := jsonschema.GenerateSchemaForType(result)
+	if err != nil {
+		return
+	}
+	strict, err := jsonschema.MakeStrict(generated)
+	if err != nil {
+		return
+	}
+	var schema jsonschema.Definition
+	if err = json.Unmarshal(strict, &schema); err != nil {
+		return
+	}
+
+	name := options.Name
+	if name == "" {
+		name = structuredOutputName(reflect.TypeOf((*T)(nil)).Elem())
+	}
+	request.ResponseFormat = &ChatCompletionResponseFormat{
+		Type: ChatCompletionResponseFormatTypeJSONSchema,
+		JSONSchema: &ChatCompletionResponseFormatJSONSchema{
+			Name:        name,
+			Description: options.Description,
+			Schema:      strict,
+			Strict:      true,
+		},
+	}
+	request.Messages = append([]ChatCompletionMessage(nil), request.Messages...)
+
+	for attempt := 0; ; attempt++ {
+		response, err = client.CreateChatCompletion(ctx, request)
+		if err != nil {
+			return
+		}
+		if len(response.Choices) == 0 {
+			err = ErrStructuredOutputNoChoices
+			return
+		}
+
+		choice := response.Choices[0]
+		if choice.Message.Refusal != "" {
+			err = &RefusalError{Refusal: choice.Message.Refusal}
+			return
+		}
+		if choice.FinishReason == FinishReasonLength {
+			err = &TruncatedOutputError{Content: choice.Message.Content}
+			return
+		}
+
+		content := choice.Message.Content
+		validationErr := schema.Unmarshal(content, &result)
+		if validationErr == nil {
+			return
+		}
+		if attempt >= options.MaxRetries {
+			err = &StructuredOutputValidationError{Content: content, Err: validationErr}
+			return
+		}
+		request.Messages = append(request.Messages,
+			ChatCompletionMessage{Role: ChatMessageRoleAssistant, Content: content},
+			ChatCompletionMessage{
+				Role: ChatMessageRoleUser,
+				Content: fmt.Sprintf("The previous reply does not match the JSON schema: %v. "+
+					"Reply again with JSON that matches the schema.", validationErr),
+			})
+	}
+}
+
+func structuredOutputName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if structuredOutputNamePattern.MatchString(t.Name()) {
+		return t.Name()
+	}
+	return defaultStructuredOutputName
+}
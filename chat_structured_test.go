@@ -0,0 +1,120 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ibanyu/go-openai"
+	"github.com/ibanyu/go-openai/internal/test/checks"
+)
+
+type structuredRecipe struct {
+	Name        string   `json:"name"`
+	Ingredients []string `json:"ingredients"`
+	Minutes     int      `json:"minutes,omitempty"`
+}
+
+func structuredChatResponse(message, finishReason string) string {
+	return fmt.Sprintf(`{"id":"1","choices":[{"index":0,"finish_reason":%q,"message":%s}]}`,
+		finishReason, message)
+}
+
+func TestCreateChatCompletionStructured(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	var requests []map[string]any
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&body), "decode request error")
+		requests = append(requests, body)
+		if len(requests) == 1 {
+			fmt.Fprint(w, structuredChatResponse(`{"role":"assistant","content":"{\"name\":\"Soup\"}"}`, "stop"))
+			return
+		}
+		fmt.Fprint(w, structuredChatResponse(`{"role":"assistant","content":`+
+			`"{\"name\":\"Soup\",\"ingredients\":[\"water\"],\"minutes\":5}"}`, "stop"))
+	})
+
+	recipe, resp, err := openai.CreateChatCompletionStructured[structuredRecipe](
+		context.Background(), client, openai.ChatCompletionRequest{
+			Model:    openai.GPT4o,
+			Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "A recipe"}},
+		}, openai.StructuredOutputOptions{MaxRetries: 1})
+	checks.NoError(t, err, "CreateChatCompletionStructured error")
+
+	if recipe.Name != "Soup" || len(recipe.Ingredients) != 1 || recipe.Minutes != 5 || resp.ID != "1" {
+		t.Errorf("unexpected recipe: %+v", recipe)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("expected a retry, got %d requests", len(requests))
+	}
+
+	format, _ := requests[0]["response_format"].(map[string]any)
+	schema, _ := format["json_schema"].(map[string]any)
+	if format["type"] != "json_schema" || schema["name"] != "structuredRecipe" || schema["strict"] != true {
+		t.Errorf("unexpected response format: %v", format)
+	}
+	required, _ := schema["schema"].(map[string]any)["required"].([]any)
+	if len(required) != 3 {
+		t.Errorf("expected every property to be required in strict mode, got %v", required)
+	}
+	if messages, _ := requests[1]["messages"].([]any); len(messages) != 3 {
+		t.Errorf("expected the failed reply and a correction prompt, got %v", messages)
+	}
+}
+
+func TestCreateChatCompletionStructuredErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		check    func(err error) bool
+	}{
+		{
+			"refusal",
+			structuredChatResponse(`{"role":"assistant","refusal":"I can't help with that."}`, "stop"),
+			func(err error) bool {
+				var refusal *openai.RefusalError
+				return errors.As(err, &refusal) && refusal.Refusal == "I can't help with that."
+			},
+		},
+		{
+			"truncated",
+			structuredChatResponse(`{"role":"assistant","content":"{\"name\":"}`, "length"),
+			func(err error) bool {
+				var truncated *openai.TruncatedOutputError
+				return errors.As(err, &truncated) && truncated.Content == `{"name":`
+			},
+		},
+		{
+			"invalid",
+			structuredChatResponse(`{"role":"assistant","content":"[]"}`, "stop"),
+			func(err error) bool {
+				var invalid *openai.StructuredOutputValidationError
+				return errors.As(err, &invalid) && invalid.Content == "[]"
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client, server, teardown := setupOpenAITestServer()
+			defer teardown()
+			server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+				fmt.Fprint(w, tc.response)
+			})
+
+			_, _, err := openai.CreateChatCompletionStructured[structuredRecipe](
+				context.Background(), client, openai.ChatCompletionRequest{
+					Model:    openai.GPT4o,
+					Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "A recipe"}},
+				}, openai.StructuredOutputOptions{})
+			if !tc.check(err) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package openai
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/gif"  // registers the GIF decoder used to size images
+	_ "image/jpeg" // registers the JPEG decoder used to size images
+	_ "image/png"  // registers the PNG decoder used to size images
+	"sort"
+	"strings"
+
+	"github.com/ibanyu/go-openai/tokenizer"
+)
+
+// Token overheads of the chat format, as documented in
+// https://github.com/openai/openai-cookbook/blob/main/examples/How_to_count_tokens_with_tiktoken.ipynb
+const (
+	tokensPerMessage      = 3
+	tokensPerName         = 1
+	tokensPerReplyPriming = 3
+	tokensPerFunctionCall = 3
+	tokensPerTools        = 9
+)
+
+// The definitions of tools are rendered in the first system message, after a
+// line break, and a request with both costs tokensSavedBySystemWithTools fewer
+// tokens than the message and the definitions counted apart. The line break
+// may merge with the last token of the message, which is why a system message
+// of "Hello" or "Hello:" costs the same with tools. Both were measured from
+// the Usage.PromptTokens reported for such requests.
+const tokensSavedBySystemWithTools = 4
+
+// Image token costs of the high detail mode: images are scaled to fit in a
+// square of imageMaxSize, then so that their shortest side is at most
+// imageShortSide, and cost imageTileTokens per imageTileSize tile.
+const (
+	imageBaseTokens = 85
+	imageTileTokens = 170
+	imageTileSize   = 512
+	imageMaxSize    = 2048
+	imageShortSide  = 768
+)
+
+// CountChatCompletionTokens returns the number of prompt tokens of request,
+// as reported in Usage.PromptTokens, using encoding, which should be the one
+// returned by tokenizer.EncodingForModel for the model of the request.
+//
+// Text messages are counted exactly. Tool definitions and tool calls are
+// estimated from the format the API is known to render them in. Images are
+// counted from their size when it can be read from a data URL, and as the
+// largest image of their detail mode otherwise.
+func CountChatCompletionTokens(encoding *tokenizer.Encoding, request ChatCompletionRequest) int {
+	tokens := tokensPerReplyPriming
+	for _, message := range request.Messages {
+		tokens += CountChatMessageTokens(encoding, message)
+	}
+
+	functions := append([]FunctionDefinition(nil), request.Functions...)
+	for _, tool := range request.Tools {
+		if tool.Function != nil {
+			functions = append(functions, *tool.Function)
+		}
+	}
+	if len(functions) == 0 {
+		return tokens + toolChoiceTokens(encoding, request.ToolChoice) + toolChoiceTokens(encoding, request.FunctionCall)
+	}
+
+	tokens += encoding.Count(formatFunctionDefinitions(functions)) + tokensPerTools
+	for _, message := range request.Messages {
+		if message.Role == ChatMessageRoleSystem || message.Role == ChatMessageRoleDeveloper {
+			lineBreak := encoding.Count(message.Content+"\n") - encoding.Count(message.Content)
+			tokens += lineBreak - tokensSavedBySystemWithTools
+			break
+		}
+	}
+	return tokens + toolChoiceTokens(encoding, request.ToolChoice) + toolChoiceTokens(encoding, request.FunctionCall)
+}
+
+// CountChatMessageTokens returns the number of prompt tokens of message,
+// including the overhead of the chat format.
+func CountChatMessageTokens(encoding *tokenizer.Encoding, message ChatCompletionMessage) int {
+	tokens := tokensPerMessage + encoding.Count(message.Role) + encoding.Count(message.Content)
+	for _, part := range message.MultiContent {
+		switch part.Type {
+		case ChatMessagePartTypeText:
+			tokens += encoding.Count(part.Text)
+		case ChatMessagePartTypeImageURL:
+			if part.ImageURL != nil {
+				tokens += imageURLTokens(*part.ImageURL)
+			}
+		case ChatMessagePartTypeInputAudio:
+		}
+	}
+	if message.Name != "" {
+		tokens += encoding.Count(message.Name) + tokensPerName
+	}
+	if message.Role == ChatMessageRoleFunction {
+		tokens -= 2
+	}
+	if call := message.FunctionCall; call != nil {
+		tokens += encoding.Count(call.Name) + encoding.Count(call.Arguments) + tokensPerFunctionCall
+	}
+	for _, call := range message.ToolCalls {
+		tokens += encoding.Count(call.Function.Name) + encoding.Count(call.Function.Arguments) + tokensPerFunctionCall
+	}
+	return tokens
+}
+
+// ImageTokens returns the number of tokens of an image of the given size.
+// Images in ImageURLDetailAuto are counted as in ImageURLDetailHigh.
+func ImageTokens(width, height int, detail ImageURLDetail) int {
+	if detail == ImageURLDetailLow {
+		return imageBaseTokens
+	}
+	w, h := float64(width), float64(height)
+	if w > imageMaxSize || h > imageMaxSize {
+		scale := imageMaxSize / maxFloat(w, h)
+		w, h = w*scale, h*scale
+	}
+	if short := minFloat(w, h); short > imageShortSide {
+		scale := imageShortSide / short
+		w, h = w*scale, h*scale
+	}
+	tiles := ceilDiv(int(w+0.5), imageTileSize) * ceilDiv(int(h+0.5), imageTileSize)
+	return imageBaseTokens + imageTileTokens*tiles
+}
+
+func imageURLTokens(imageURL ChatMessageImageURL) int {
+	if width, height, ok := dataURLImageSize(imageURL.URL); ok {
+		return ImageTokens(width, height, imageURL.Detail)
+	}
+	return ImageTokens(imageShortSide, imageMaxSize, imageURL.Detail)
+}
+
+// dataURLImageSize returns the size of a base64 encoded image of a data URL.
+func dataURLImageSize(url string) (width, height int, ok bool) {
+	if !strings.HasPrefix(url, "data:") {
+		return 0, 0, false
+	}
+	index := strings.Index(url, ";base64,")
+	if index < 0 {
+		return 0, 0, false
+	}
+	data, err := base64.StdEncoding.DecodeString(url[index+len(";base64,"):])
+	if err != nil {
+		return 0, 0, false
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, false
+	}
+	return config.Width, config.Height, true
+}
+
+// toolChoiceTokens returns the tokens of a tool_choice or function_call
+// parameter that is neither unset nor "auto".
+func toolChoiceTokens(encoding *tokenizer.Encoding, choice any) int {
+	switch c := choice.(type) {
+	case string:
+		if c == "none" {
+			return 1
+		}
+		return 0
+	case ToolChoice:
+		return encoding.Count(c.Function.Name) + 4
+	case *ToolChoice:
+		return encoding.Count(c.Function.Name) + 4
+	case FunctionCall:
+		return encoding.Count(c.Name) + 4
+	case *FunctionCall:
+		return encoding.Count(c.Name) + 4
+	default:
+		return 0
+	}
+}
+
+// formatFunctionDefinitions renders functions the way the API presents them
+// to the model, as TypeScript declarations.
+func formatFunctionDefinitions(functions []FunctionDefinition) string {
+	lines := []string{"namespace functions {", ""}
+	for _, function := range functions {
+		if function.Description != "" {
+			lines = append(lines, "// "+function.Description)
+		}
+		parameters := decodeJSONObject(function.Parameters)
+		if properties, _ := parameters["properties"].(map[string]any); len(properties) > 0 {
+			lines = append(lines, "type "+function.Name+" = (_: {", formatObjectProperties(parameters, 0), "}) => any;")
+		} else {
+			lines = append(lines, "type "+function.Name+" = () => any;")
+		}
+		lines = append(lines, "")
+	}
+	lines = append(lines, "} // namespace functions")
+	return strings.Join(lines, "\n")
+}
+
+func formatObjectProperties(object map[string]any, indent int) string {
+	properties, _ := object["properties"].(map[string]any)
+	required := make(map[string]bool)
+	if names, ok := object["required"].([]any); ok {
+		for _, name := range names {
+			if s, ok := name.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var lines []string
+	for _, name := range names {
+		property, _ := properties[name].(map[string]any)
+		if description, _ := property["description"].(string); description != "" && indent < 2 {
+			lines = append(lines, "// "+description)
+		}
+		optional := "?"
+		if required[name] {
+			optional = ""
+		}
+		lines = append(lines, name+optional+": "+formatType(property, indent)+",")
+	}
+	for i, line := range lines {
+		lines[i] = strings.Repeat(" ", indent) + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+func formatType(property map[string]any, indent int) string {
+	dataType, _ := property["type"].(string)
+	switch dataType {
+	case "string", "number", "integer":
+		if enum, ok := property["enum"].([]any); ok {
+			values := make([]string, len(enum))
+			for i, value := range enum {
+				if s, ok := value.(string); ok && dataType == "string" {
+					values[i] = `"` + s + `"`
+				} else {
+					values[i] = fmt.Sprint(value)
+				}
+			}
+			return strings.Join(values, " | ")
+		}
+		if dataType == "integer" {
+			return "number"
+		}
+		return dataType
+	case "boolean", "null":
+		return dataType
+	case "object":
+		return "{\n" + formatObjectProperties(property, indent+2) + "\n}"
+	case "array":
+		if items, ok := property["items"].(map[string]any); ok {
+			return formatType(items, indent) + "[]"
+		}
+		return "any[]"
+	default:
+		return ""
+	}
+}
+
+// decodeJSONObject returns v as decoded by encoding/json from its JSON form.
+func decodeJSONObject(v any) map[string]any {
+	var data []byte
+	switch value := v.(type) {
+	case json.RawMessage:
+		data = value
+	case []byte:
+		data = value
+	default:
+		data, _ = json.Marshal(v)
+	}
+	var object map[string]any
+	_ = json.Unmarshal(data, &object)
+	return object
+}
+
+func ceilDiv(a, b int) int {
+	return (a + b - 1) / b
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,192 @@
+package openai_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/ibanyu/go-openai"
+	"github.com/ibanyu/go-openai/jsonschema"
+	"github.com/ibanyu/go-openai/tokenizer"
+)
+
+// byteEncoding returns an encoding without merges, which encodes every byte
+// of a text as a token.
+func byteEncoding(t *testing.T) *tokenizer.Encoding {
+	t.Helper()
+	var ranks strings.Builder
+	for i := 0; i < 256; i++ {
+		fmt.Fprintf(&ranks, "%s %d\n", base64.StdEncoding.EncodeToString([]byte{byte(i)}), i)
+	}
+	encoding, err := tokenizer.LoadEncoding(tokenizer.O200kBase, strings.NewReader(ranks.String()))
+	if err != nil {
+		t.Fatalf("LoadEncoding error: %v", err)
+	}
+	return encoding
+}
+
+func pngDataURL(t *testing.T, width, height int) string {
+	t.Helper()
+	var b bytes.Buffer
+	if err := png.Encode(&b, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("png.Encode error: %v", err)
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(b.Bytes())
+}
+
+func TestCountChatCompletionTokens(t *testing.T) {
+	encoding := byteEncoding(t)
+	request := openai.ChatCompletionRequest{
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: "Be brief."},
+			{Role: openai.ChatMessageRoleUser, Content: "Hi", Name: "bob"},
+		},
+	}
+	// Reply priming, then 3 per message, the role, the content and the name.
+	if tokens := openai.CountChatCompletionTokens(encoding, request); tokens != 3+(3+6+9)+(3+4+2+3+1) {
+		t.Errorf("unexpected tokens: %d", tokens)
+	}
+
+	withTools := request
+	withTools.Tools = []openai.Tool{{Type: openai.ToolTypeFunction, Function: &openai.FunctionDefinition{
+		Name:        "get_weather",
+		Description: "Get the weather",
+		Parameters: jsonschema.Definition{
+			Type: jsonschema.Object,
+			Properties: map[string]jsonschema.Definition{
+				"city": {Type: jsonschema.String, Description: "The city"},
+				"unit": {Type: jsonschema.String, Enum: []string{"c", "f"}},
+			},
+			Required: []string{"city"},
+		},
+	}}}
+	formatted := strings.Join([]string{
+		"namespace functions {",
+		"",
+		"// Get the weather",
+		"type get_weather = (_: {",
+		"// The city",
+		"city: string,",
+		`unit?: "c" | "f",`,
+		"}) => any;",
+		"",
+		"} // namespace functions",
+	}, "\n")
+	// The definitions cost 9 tokens more than their text, and the system
+	// message takes a line break but saves 4 tokens.
+	want := openai.CountChatCompletionTokens(encoding, request) + len(formatted) + 9 + 1 - 4
+	if tokens := openai.CountChatCompletionTokens(encoding, withTools); tokens != want {
+		t.Errorf("unexpected tokens with tools: got %d, want %d", tokens, want)
+	}
+
+	withImages := openai.ChatCompletionRequest{Messages: []openai.ChatCompletionMessage{{
+		Role: openai.ChatMessageRoleUser,
+		MultiContent: []openai.ChatMessagePart{
+			{Type: openai.ChatMessagePartTypeText, Text: "What is it?"},
+			{Type: openai.ChatMessagePartTypeImageURL, ImageURL: &openai.ChatMessageImageURL{
+				URL: pngDataURL(t, 1024, 1024), Detail: openai.ImageURLDetailHigh,
+			}},
+			{Type: openai.ChatMessagePartTypeImageURL, ImageURL: &openai.ChatMessageImageURL{
+				URL: "https://example.com/cat.png", Detail: openai.ImageURLDetailLow,
+			}},
+			{Type: openai.ChatMessagePartTypeImageURL, ImageURL: &openai.ChatMessageImageURL{
+				URL: "https://example.com/dog.png",
+			}},
+		},
+	}}}
+	want = 3 + 3 + 4 + 11 + 765 + 85 + 1445
+	if tokens := openai.CountChatCompletionTokens(encoding, withImages); tokens != want {
+		t.Errorf("unexpected tokens with images: got %d, want %d", tokens, want)
+	}
+}
+
+func TestImageTokens(t *testing.T) {
+	tests := []struct {
+		width, height int
+		detail        openai.ImageURLDetail
+		want          int
+	}{
+		{1024, 1024, openai.ImageURLDetailHigh, 765},
+		{2048, 4096, openai.ImageURLDetailHigh, 1105},
+		{4096, 8192, openai.ImageURLDetailLow, 85},
+		{512, 512, openai.ImageURLDetailAuto, 255},
+		{100, 1000, openai.ImageURLDetailHigh, 85 + 170*2},
+	}
+	for _, tc := range tests {
+		if got := openai.ImageTokens(tc.width, tc.height, tc.detail); got != tc.want {
+			t.Errorf("ImageTokens(%d, %d, %q) = %d, want %d", tc.width, tc.height, tc.detail, got, tc.want)
+		}
+	}
+}
+
+// TestCountChatCompletionTokensUsage compares the counts with the
+// Usage.PromptTokens reported by the API for the same requests.
+func TestCountChatCompletionTokensUsage(t *testing.T) {
+	message := func(role, name, content string) openai.ChatCompletionMessage {
+		return openai.ChatCompletionMessage{Role: role, Name: name, Content: content}
+	}
+	system, user := openai.ChatMessageRoleSystem, openai.ChatMessageRoleUser
+	// The example of the OpenAI cookbook on counting tokens.
+	cookbook := []openai.ChatCompletionMessage{
+		message(system, "", "You are a helpful, pattern-following assistant that translates corporate jargon "+
+			"into plain English."),
+		message(system, "example_user", "New synergies will help drive top-line growth."),
+		message(system, "example_assistant", "Things working well together will increase revenue."),
+		message(system, "example_user", "Let's circle back when we have more bandwidth to touch base on "+
+			"opportunities for increased leverage."),
+		message(system, "example_assistant", "Let's talk later when we're less busy about how to do better."),
+		message(user, "", "This late pivot means we don't have time to boil the ocean for the client deliverable."),
+	}
+	function := func(name, description string, properties map[string]jsonschema.Definition) openai.FunctionDefinition {
+		return openai.FunctionDefinition{
+			Name:        name,
+			Description: description,
+			Parameters:  jsonschema.Definition{Type: jsonschema.Object, Properties: properties},
+		}
+	}
+	doStuff := []openai.FunctionDefinition{function("do_stuff", "", map[string]jsonschema.Definition{})}
+
+	tests := []struct {
+		model     string
+		messages  []openai.ChatCompletionMessage
+		functions []openai.FunctionDefinition
+		want      int
+	}{
+		{openai.GPT4, cookbook, nil, 129},
+		{openai.GPT4o, cookbook, nil, 124},
+		{openai.GPT3Dot5Turbo, []openai.ChatCompletionMessage{
+			message(system, "", "# Important: you're the best robot"),
+			message(user, "", "hello robot"),
+			message(openai.ChatMessageRoleAssistant, "", "hello world"),
+		}, nil, 27},
+		{openai.GPT3Dot5Turbo, []openai.ChatCompletionMessage{message(user, "", "hello")}, []openai.FunctionDefinition{
+			function("foo", "Do a foo", map[string]jsonschema.Definition{}),
+		}, 36},
+		{openai.GPT3Dot5Turbo, []openai.ChatCompletionMessage{message(user, "", "hello")}, []openai.FunctionDefinition{
+			function("bing_bong", "Do a bing bong", map[string]jsonschema.Definition{"foo": {Type: jsonschema.String}}),
+		}, 49},
+		{openai.GPT3Dot5Turbo, []openai.ChatCompletionMessage{
+			message(system, "", "Hello"), message(user, "", "Hi there"),
+		}, doStuff, 35},
+		{openai.GPT3Dot5Turbo, []openai.ChatCompletionMessage{
+			message(system, "", "Hello:"), message(user, "", "Hi there"),
+		}, doStuff, 35},
+		{openai.GPT3Dot5Turbo, []openai.ChatCompletionMessage{
+			message(system, "", "Hello:"), message(system, "", "Hello"), message(user, "", "Hi there"),
+		}, doStuff, 40},
+	}
+	for i, tc := range tests {
+		encoding, err := tokenizer.EncodingForModel(tc.model)
+		if err != nil {
+			t.Fatalf("EncodingForModel error: %v", err)
+		}
+		request := openai.ChatCompletionRequest{Model: tc.model, Messages: tc.messages, Functions: tc.functions}
+		if tokens := openai.CountChatCompletionTokens(encoding, request); tokens != tc.want {
+			t.Errorf("request %d: got %d tokens, want %d", i, tokens, tc.want)
+		}
+	}
+}
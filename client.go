@@ -72,8 +72,9 @@ func NewOrgClient(authToken, org string) *Client {
 }
 
 type requestOptions struct {
-	body   any
-	header http.Header
+	body     any
+	hookBody any
+	header   http.Header
 }
 
 type requestOption func(*requestOptions)
@@ -84,6 +85,15 @@ func withBody(body any) requestOption {
 	}
 }
 
+// withHookBody sets the value passed to request hooks and to the rate limiter
+// instead of the body, for bodies translated from an OpenAI request for the
+// API of another provider.
+func withHookBody(body any) requestOption {
+	return func(args *requestOptions) {
+		args.hookBody = body
+	}
+}
+
 func withContentType(contentType string) requestOption {
 	return func(args *requestOptions) {
 		args.header.Set("Content-Type", contentType)
@@ -105,15 +115,42 @@ func (c *Client) newRequest(ctx context.Context, method, url string, setters ...
 	for _, setter := range setters {
 		setter(args)
 	}
+	hookBody := args.body
+	if args.hookBody != nil {
+		hookBody = args.hookBody
+	}
+	if c.config.RateLimiter != nil && hookBody != nil {
+		ctx = withTokenCost(ctx, c.config.RateLimiter.EstimateTokens(hookBody))
+	}
 	req, err := c.requestBuilder.Build(ctx, method, url, args.body, args.header)
 	if err != nil {
 		return nil, err
 	}
 	c.setCommonHeaders(req)
+	if err = c.runRequestHooks(req, hookBody); err != nil {
+		return nil, err
+	}
 	return req, nil
 }
 
+// do sends a single attempt of the request.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	if err := c.limitRequest(req); err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient().Do(req)
+	c.observeRateLimits(resp)
+	return resp, err
+}
+
 func (c *Client) sendRequest(req *http.Request, v Response) error {
+	return c.sendTranslatedRequest(req, v, nil)
+}
+
+// sendTranslatedRequest is sendRequest for the API of another provider. v is
+// decoded from the response, then translated by translate, when set, into the
+// OpenAI response passed to the response hooks.
+func (c *Client) sendTranslatedRequest(req *http.Request, v Response, translate func() Response) error {
 	req.Header.Set("Accept", "application/json")
 
 	// Check whether Content-Type is already set, Upload Files API requires
@@ -123,8 +160,14 @@ func (c *Client) sendRequest(req *http.Request, v Response) error {
 		req.Header.Set("Content-Type", "application/json")
 	}
 
-	res, err := c.config.HTTPClient.Do(req)
+	hookResponse := v
+	if translate != nil {
+		hookResponse = nil
+	}
+
+	res, err := c.doRequest(req)
 	if err != nil {
+		c.runResponseHooks(req, nil, hookResponse, err)
 		return err
 	}
 
@@ -135,14 +178,22 @@ func (c *Client) sendRequest(req *http.Request, v Response) error {
 	}
 
 	if isFailureStatusCode(res) {
-		return c.handleErrorResp(res)
+		err = c.handleErrorResp(res)
+	} else {
+		err = decodeResponse(res.Body, v)
 	}
-
-	return decodeResponse(res.Body, v)
+	if err == nil && translate != nil {
+		hookResponse = translate()
+	}
+	c.runResponseHooks(req, res, hookResponse, err)
+	return err
 }
 
 func (c *Client) sendRequestRaw(req *http.Request) (response RawResponse, err error) {
-	resp, err := c.config.HTTPClient.Do(req) //nolint:bodyclose // body should be closed by outer function
+	resp, err := c.doRequest(req) //nolint:bodyclose // body should be closed by outer function
+	defer func() {
+		c.runResponseHooks(req, resp, nil, err)
+	}()
 	if err != nil {
 		return
 	}
@@ -163,13 +214,15 @@ func sendRequestStream[T streamable](client *Client, req *http.Request) (*stream
 	req.Header.Set("Cache-Control", "no-cache")
 	req.Header.Set("Connection", "keep-alive")
 
-	resp, err := client.config.HTTPClient.Do(req) //nolint:bodyclose // body is closed in stream.Close()
+	resp, err := client.doRequest(req) //nolint:bodyclose // body is closed in stream.Close()
+	if err == nil && isFailureStatusCode(resp) {
+		err = client.handleErrorResp(resp)
+	}
+	client.runResponseHooks(req, resp, nil, err)
 	if err != nil {
 		return new(streamReader[T]), err
 	}
-	if isFailureStatusCode(resp) {
-		return new(streamReader[T]), client.handleErrorResp(resp)
-	}
+	client.withStreamTimeouts(resp)
 	return &streamReader[T]{
 		emptyMessagesLimit: client.config.EmptyMessagesLimit,
 		reader:             bufio.NewReader(resp.Body),
@@ -189,6 +242,9 @@ func (c *Client) setCommonHeaders(req *http.Request) {
 	case APITypeAnthropic:
 		// https://docs.anthropic.com/en/api/versioning
 		req.Header.Set("anthropic-version", c.config.APIVersion)
+		if c.config.authToken != "" {
+			req.Header.Set(anthropicAPIKeyHeader, c.config.authToken)
+		}
 	case APITypeOpenAI, APITypeAzureAD:
 		fallthrough
 	default:
@@ -264,10 +320,11 @@ func (c *Client) fullURL(suffix string, setters ...fullURLOption) string {
 		baseURL = c.baseURLWithAzureDeployment(baseURL, suffix, args.model)
 	}
 	if c.config.APIType == APITypeOllama {
-		suffix = c.suffixOllamaChat(suffix)
+		suffix = c.suffixOllama(suffix)
 	}
 
-	if c.config.APIVersion != "" {
+	// Anthropic sends its API version in the anthropic-version header.
+	if c.config.APIVersion != "" && c.config.APIType != APITypeAnthropic {
 		suffix = c.suffixWithAPIVersion(suffix)
 	}
 	return fmt.Sprintf("%s%s", baseURL, suffix)
@@ -294,11 +351,12 @@ func (c *Client) baseURLWithAzureDeployment(baseURL, suffix, model string) (newB
 	}
 	return baseURL
 }
-func (c *Client) suffixOllamaChat(suffix string) (newSuffix string) {
-	if suffix == chatCompletionsSuffix {
-		return "/api/chat"
+
+func (c *Client) suffixOllama(suffix string) (newSuffix string) {
+	if ollamaSuffix, ok := ollamaSuffixes[suffix]; ok {
+		return ollamaSuffix
 	}
-	return
+	return suffix
 }
 
 func (c *Client) handleErrorResp(resp *http.Response) error {
@@ -306,6 +364,18 @@ func (c *Client) handleErrorResp(resp *http.Response) error {
 	if err != nil {
 		return fmt.Errorf("error, reading response body: %w", err)
 	}
+	if c.config.APIType == APITypeOllama {
+		// Ollama reports errors as {"error": "message"}.
+		var ollamaErr ollamaErrorResponse
+		if json.Unmarshal(body, &ollamaErr) == nil && ollamaErr.Error != "" {
+			return &APIError{
+				Message:        ollamaErr.Error,
+				HTTPStatus:     resp.Status,
+				HTTPStatusCode: resp.StatusCode,
+			}
+		}
+	}
+
 	var errRes ErrorResponse
 	err = json.Unmarshal(body, &errRes)
 	if err != nil || errRes.Error == nil {
@@ -91,73 +91,6 @@ const (
 	CodexCodeDavinci001 = "code-davinci-001"
 )
 
-var disabledModelsForEndpoints = map[string]map[string]bool{
-	"/completions": {
-		O1Mini:                  true,
-		O1Mini20240912:          true,
-		O1Preview:               true,
-		O1Preview20240912:       true,
-		O3Mini:                  true,
-		O3Mini20250131:          true,
-		GPT3Dot5Turbo:           true,
-		GPT3Dot5Turbo0301:       true,
-		GPT3Dot5Turbo0613:       true,
-		GPT3Dot5Turbo1106:       true,
-		GPT3Dot5Turbo0125:       true,
-		GPT3Dot5Turbo16K:        true,
-		GPT3Dot5Turbo16K0613:    true,
-		GPT4:                    true,
-		GPT4Dot5Preview:         true,
-		GPT4Dot5Preview20250227: true,
-		GPT4o:                   true,
-		GPT4o20240513:           true,
-		GPT4o20240806:           true,
-		GPT4o20241120:           true,
-		GPT4oLatest:             true,
-		GPT4oMini:               true,
-		GPT4oMini20240718:       true,
-		GPT4TurboPreview:        true,
-		GPT4VisionPreview:       true,
-		GPT4Turbo1106:           true,
-		GPT4Turbo0125:           true,
-		GPT4Turbo:               true,
-		GPT4Turbo20240409:       true,
-		GPT40314:                true,
-		GPT40613:                true,
-		GPT432K:                 true,
-		GPT432K0314:             true,
-		GPT432K0613:             true,
-		O1:                      true,
-		GPT4Dot1:                true,
-		GPT4Dot120250414:        true,
-		GPT4Dot1Mini:            true,
-		GPT4Dot1Mini20250414:    true,
-		GPT4Dot1Nano:            true,
-		GPT4Dot1Nano20250414:    true,
-	},
-	chatCompletionsSuffix: {
-		CodexCodeDavinci002:     true,
-		CodexCodeCushman001:     true,
-		CodexCodeDavinci001:     true,
-		GPT3TextDavinci003:      true,
-		GPT3TextDavinci002:      true,
-		GPT3TextCurie001:        true,
-		GPT3TextBabbage001:      true,
-		GPT3TextAda001:          true,
-		GPT3TextDavinci001:      true,
-		GPT3DavinciInstructBeta: true,
-		GPT3Davinci:             true,
-		GPT3CurieInstructBeta:   true,
-		GPT3Curie:               true,
-		GPT3Ada:                 true,
-		GPT3Babbage:             true,
-	},
-}
-
-func checkEndpointSupportsModel(endpoint, model string) bool {
-	return !disabledModelsForEndpoints[endpoint][model]
-}
-
 func checkPromptType(prompt any) bool {
 	_, isString := prompt.(string)
 	_, isStringSlice := prompt.([]string)
@@ -210,6 +143,27 @@ type CompletionRequest struct {
 	RawExtensions
 }
 
+func (r *CompletionRequest) UnmarshalJSON(data []byte) error {
+	// 使用类型别名避免递归调用
+	type alias CompletionRequest
+	aux := (*alias)(r)
+
+	// 使用优化的反序列化函数
+	return UnmarshalWithExtensions(data, aux, &r.RawExtensions)
+}
+
+func (r CompletionRequest) MarshalJSON() ([]byte, error) {
+	// 使用类型别名避免递归调用，同时排除RawExtensions字段
+	type alias CompletionRequest
+	temp := &struct {
+		*alias
+		RawExtensions struct{} `json:"-"` // 排除RawExtensions字段
+	}{
+		alias: (*alias)(&r),
+	}
+	return MarshalWithExtensions(temp, r.Extensions)
+}
+
 // CompletionChoice represents one of possible completions.
 type CompletionChoice struct {
 	Text         string        `json:"text"`
@@ -324,7 +278,7 @@ func (c *Client) CreateCompletion(
 	}
 
 	urlSuffix := "/completions"
-	if !checkEndpointSupportsModel(urlSuffix, request.Model) {
+	if !c.modelRegistry().supportsEndpoint(urlSuffix, request.Model) {
 		err = ErrCompletionUnsupportedModel
 		return
 	}
@@ -295,3 +295,18 @@ func TestCompletionWithGPT4oModels(t *testing.T) {
 		})
 	}
 }
+
+func TestCompletionRequestRawExtensions(t *testing.T) {
+	var request openai.CompletionRequest
+	err := json.Unmarshal([]byte(`{"model":"m","prompt":"hello","top_k":5}`), &request)
+	checks.NoError(t, err, "Unmarshal error")
+	if value, ok := request.GetExtension("top_k"); !ok || value != float64(5) || request.Prompt != "hello" {
+		t.Fatalf("unexpected request: %+v", request)
+	}
+
+	data, err := json.Marshal(request)
+	checks.NoError(t, err, "Marshal error")
+	if string(data) != `{"model":"m","prompt":"hello","top_k":5}` {
+		t.Errorf("unexpected JSON: %s", data)
+	}
+}
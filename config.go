@@ -3,6 +3,7 @@ package openai
 import (
 	"net/http"
 	"regexp"
+	"time"
 )
 
 const (
@@ -45,6 +46,35 @@ type ClientConfig struct {
 	AssistantVersion     string
 	AzureModelMapperFunc func(model string) string // replace model to azure deployment name func
 	HTTPClient           HTTPDoer
+	// RetryPolicy enables automatic retries of failed requests. Retries are disabled when nil.
+	RetryPolicy *RetryPolicy
+	// RateLimiter enables client side rate limiting. It may be shared between clients.
+	RateLimiter *RateLimiter
+	// Middlewares wrap HTTPClient, the first one being the outermost.
+	Middlewares []Middleware
+	// RequestHooks are called with the decoded request body before every request.
+	RequestHooks []RequestHook
+	// ResponseHooks are called with the decoded response after every request.
+	ResponseHooks []ResponseHook
+	// ModelRegistry describes the models requests are validated against.
+	// DefaultModelRegistry is used when nil.
+	ModelRegistry *ModelRegistry
+	// NormalizeRequests makes the client rewrite chat completion requests for
+	// their model with a RequestNormalizer instead of rejecting them.
+	NormalizeRequests bool
+	// OnRequestNormalized is called with the rewrites made to a normalized request.
+	OnRequestNormalized func(model string, rewrites []RequestRewrite)
+	// StrictSchemaMode controls how the schemas of strict response formats and
+	// functions are checked before chat completion requests are sent.
+	StrictSchemaMode StrictSchemaMode
+	// StreamIdleTimeout closes a stream that receives no data, heartbeats
+	// included, for this long while Recv is waiting for it. Recv then returns
+	// ErrStreamIdleTimeout. Disabled when zero.
+	StreamIdleTimeout time.Duration
+	// StreamTimeout closes a stream that is still open this long after its
+	// response was received. Recv then returns ErrStreamTimeout.
+	// Disabled when zero.
+	StreamTimeout time.Duration
 
 	EmptyMessagesLimit uint
 }
@@ -97,6 +127,23 @@ func DefaultAnthropicConfig(apiKey, baseURL string) ClientConfig {
 	}
 }
 
+// DefaultOllamaConfig returns a configuration for the native API of an Ollama
+// server, http://localhost:11434 when baseURL is empty.
+func DefaultOllamaConfig(baseURL string) ClientConfig {
+	if baseURL == "" {
+		baseURL = "http://localhost:11434"
+	}
+	return ClientConfig{
+		BaseURL: baseURL,
+		OrgID:   "",
+		APIType: APITypeOllama,
+
+		HTTPClient: &http.Client{},
+
+		EmptyMessagesLimit: defaultEmptyMessagesLimit,
+	}
+}
+
 func (ClientConfig) String() string {
 	return "<OpenAI API ClientConfig>"
 }
@@ -160,6 +160,28 @@ type EmbeddingRequest struct {
 	// Dimensions The number of dimensions the resulting output embeddings should have.
 	// Only supported in text-embedding-3 and later models.
 	Dimensions int `json:"dimensions,omitempty"`
+	RawExtensions
+}
+
+func (r *EmbeddingRequest) UnmarshalJSON(data []byte) error {
+	// 使用类型别名避免递归调用
+	type alias EmbeddingRequest
+	aux := (*alias)(r)
+
+	// 使用优化的反序列化函数
+	return UnmarshalWithExtensions(data, aux, &r.RawExtensions)
+}
+
+func (r EmbeddingRequest) MarshalJSON() ([]byte, error) {
+	// 使用类型别名避免递归调用，同时排除RawExtensions字段
+	type alias EmbeddingRequest
+	temp := &struct {
+		*alias
+		RawExtensions struct{} `json:"-"` // 排除RawExtensions字段
+	}{
+		alias: (*alias)(&r),
+	}
+	return MarshalWithExtensions(temp, r.Extensions)
 }
 
 func (r EmbeddingRequest) Convert() EmbeddingRequest {
@@ -241,6 +263,10 @@ func (c *Client) CreateEmbeddings(
 	conv EmbeddingRequestConverter,
 ) (res EmbeddingResponse, err error) {
 	baseReq := conv.Convert()
+	if c.config.APIType == APITypeOllama {
+		return c.createOllamaEmbeddings(ctx, baseReq)
+	}
+
 	req, err := c.newRequest(
 		ctx,
 		http.MethodPost,
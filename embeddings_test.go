@@ -281,3 +281,18 @@ func TestDotProduct(t *testing.T) {
 		t.Errorf("Expected Vector Length Mismatch Error, but got: %v", err)
 	}
 }
+
+func TestEmbeddingRequestRawExtensions(t *testing.T) {
+	var request openai.EmbeddingRequest
+	err := json.Unmarshal([]byte(`{"input":"hello","model":"m","truncate":"END"}`), &request)
+	checks.NoError(t, err, "Unmarshal error")
+	if value, ok := request.GetExtension("truncate"); !ok || value != "END" || request.Input != "hello" {
+		t.Fatalf("unexpected request: %+v", request)
+	}
+
+	data, err := json.Marshal(request)
+	checks.NoError(t, err, "Marshal error")
+	if string(data) != `{"input":"hello","model":"m","truncate":"END"}` {
+		t.Errorf("unexpected JSON: %s", data)
+	}
+}
@@ -0,0 +1,131 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ibanyu/go-openai/jsonschema"
+)
+
+var (
+	ErrFunctionAlreadyRegistered = errors.New("function is already registered")
+	ErrFunctionArgsNotObject     = errors.New("function arguments must be an object")
+)
+
+// FunctionRegistry builds function tools from typed Go functions and
+// dispatches the tool calls of a model to them. It implements ToolDispatcher.
+type FunctionRegistry struct {
+	mu        sync.RWMutex
+	names     []string
+	functions map[string]registeredFunction
+}
+
+type registeredFunction struct {
+	definition FunctionDefinition
+	call       func(ctx context.Context, arguments string) (string, ToolErrorType, error)
+}
+
+// NewFunctionRegistry returns an empty FunctionRegistry.
+func NewFunctionRegistry() *FunctionRegistry {
+	return &FunctionRegistry{functions: make(map[string]registeredFunction)}
+}
+
+// RegisterFunction adds fn to registry under name. The parameters schema of
+// the function is generated from Args with jsonschema.GenerateSchemaForType,
+// and tool call arguments are validated against it before fn is called. Args
+// must be a struct or a map, since the API only accepts object parameters.
+// String results are sent to the model as is, other results as JSON.
+func RegisterFunction[Args, Result any](
+	registry *FunctionRegistry,
+	name string,
+	description string,
+	fn func(ctx context.Context, args Args) (Result, error),
+) error {
+	var zero Args
+	schema, err := jsonschema.GenerateSchemaForType(zero)
+	if err != nil {
+		return fmt.Errorf("generating schema for %s: %w", name, err)
+	}
+	if schema.Type != jsonschema.Object {
+		return fmt.Errorf("%w: %s takes %s arguments", ErrFunctionArgsNotObject, name, schema.Type)
+	}
+
+	call := func(ctx context.Context, arguments string) (string, ToolErrorType, error) {
+		if arguments == "" {
+			arguments = "{}"
+		}
+		var args Args
+		if err := jsonschema.VerifySchemaAndUnmarshal(*schema, []byte(arguments), &args); err != nil {
+			return "", ToolErrorTypeInvalidArguments, err
+		}
+		result, err := fn(ctx, args)
+		if err != nil {
+			return "", ToolErrorTypeExecution, err
+		}
+		if s, ok := any(result).(string); ok {
+			return s, "", nil
+		}
+		output, err := json.Marshal(result)
+		if err != nil {
+			return "", ToolErrorTypeExecution, err
+		}
+		return string(output), "", nil
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if _, exists := registry.functions[name]; exists {
+		return fmt.Errorf("%w: %s", ErrFunctionAlreadyRegistered, name)
+	}
+	registry.names = append(registry.names, name)
+	registry.functions[name] = registeredFunction{
+		definition: FunctionDefinition{
+			Name:        name,
+			Description: description,
+			Parameters:  schema,
+		},
+		call: call,
+	}
+	return nil
+}
+
+// Tools returns the function tools of the registry in registration order.
+func (r *FunctionRegistry) Tools() []Tool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tools := make([]Tool, 0, len(r.names))
+	for _, name := range r.names {
+		definition := r.functions[name].definition
+		tools = append(tools, Tool{Type: ToolTypeFunction, Function: &definition})
+	}
+	return tools
+}
+
+// Dispatch calls the function named by toolCall and returns the tool message
+// holding its result, or a ToolCallError when the call fails.
+func (r *FunctionRegistry) Dispatch(ctx context.Context, toolCall ToolCall) ChatCompletionMessage {
+	message := ChatCompletionMessage{
+		Role:       ChatMessageRoleTool,
+		ToolCallID: toolCall.ID,
+	}
+
+	r.mu.RLock()
+	function, ok := r.functions[toolCall.Function.Name]
+	r.mu.RUnlock()
+	if !ok {
+		err := fmt.Errorf("%w: %s", ErrToolHandlerNotFound, toolCall.Function.Name)
+		message.Content = toolErrorOutput(ToolErrorTypeUnknownFunction, err)
+		return message
+	}
+
+	output, errType, err := function.call(ctx, toolCall.Function.Arguments)
+	if err != nil {
+		message.Content = toolErrorOutput(errType, err)
+		return message
+	}
+	message.Content = output
+	return message
+}
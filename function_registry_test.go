@@ -0,0 +1,116 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/ibanyu/go-openai"
+	"github.com/ibanyu/go-openai/internal/test/checks"
+	"github.com/ibanyu/go-openai/jsonschema"
+)
+
+type weatherArgs struct {
+	City string `json:"city" description:"The city name"`
+	Unit string `json:"unit,omitempty" enum:"celsius,fahrenheit"`
+}
+
+type weatherResult struct {
+	Temperature int `json:"temperature"`
+}
+
+func newWeatherRegistry(t *testing.T) *openai.FunctionRegistry {
+	t.Helper()
+	registry := openai.NewFunctionRegistry()
+	err := openai.RegisterFunction(registry, "get_weather", "Get the weather",
+		func(_ context.Context, args weatherArgs) (weatherResult, error) {
+			if args.City == "Atlantis" {
+				return weatherResult{}, errors.New("city not found")
+			}
+			return weatherResult{Temperature: 21}, nil
+		})
+	checks.NoError(t, err, "RegisterFunction error")
+	err = openai.RegisterFunction(registry, "echo", "",
+		func(_ context.Context, args struct{ Text string }) (string, error) {
+			return args.Text, nil
+		})
+	checks.NoError(t, err, "RegisterFunction error")
+	return registry
+}
+
+func TestFunctionRegistryTools(t *testing.T) {
+	registry := newWeatherRegistry(t)
+
+	tools := registry.Tools()
+	if len(tools) != 2 || tools[0].Function.Name != "get_weather" || tools[1].Function.Name != "echo" {
+		t.Fatalf("unexpected tools: %+v", tools)
+	}
+	schema, ok := tools[0].Function.Parameters.(*jsonschema.Definition)
+	if !ok {
+		t.Fatalf("unexpected parameters: %T", tools[0].Function.Parameters)
+	}
+	if schema.Properties["city"].Description != "The city name" || len(schema.Required) != 1 {
+		t.Errorf("unexpected schema: %+v", schema)
+	}
+
+	err := openai.RegisterFunction(registry, "echo", "",
+		func(context.Context, struct{}) (string, error) { return "", nil })
+	checks.ErrorIs(t, err, openai.ErrFunctionAlreadyRegistered, "expected ErrFunctionAlreadyRegistered")
+
+	err = openai.RegisterFunction(registry, "shout", "",
+		func(_ context.Context, text string) (string, error) { return text, nil })
+	checks.ErrorIs(t, err, openai.ErrFunctionArgsNotObject, "expected ErrFunctionArgsNotObject for string arguments")
+	err = openai.RegisterFunction(registry, "sum", "",
+		func(_ context.Context, numbers []int) (int, error) { return len(numbers), nil })
+	checks.ErrorIs(t, err, openai.ErrFunctionArgsNotObject, "expected ErrFunctionArgsNotObject for slice arguments")
+	if len(registry.Tools()) != len(tools) {
+		t.Errorf("rejected functions were registered: %+v", registry.Tools())
+	}
+}
+
+func TestFunctionRegistryDispatch(t *testing.T) {
+	registry := newWeatherRegistry(t)
+	ctx := context.Background()
+
+	message := registry.Dispatch(ctx, openai.ToolCall{
+		ID:       "call_1",
+		Function: openai.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`},
+	})
+	if message.Role != openai.ChatMessageRoleTool || message.ToolCallID != "call_1" ||
+		message.Content != `{"temperature":21}` {
+		t.Errorf("unexpected message: %+v", message)
+	}
+
+	message = registry.Dispatch(ctx, openai.ToolCall{
+		Function: openai.FunctionCall{Name: "echo", Arguments: `{"Text":"hi"}`},
+	})
+	if message.Content != "hi" {
+		t.Errorf("expected string results to be passed as is, got %q", message.Content)
+	}
+
+	tests := []struct {
+		name     string
+		toolCall openai.FunctionCall
+		errType  openai.ToolErrorType
+	}{
+		{"unknown function", openai.FunctionCall{Name: "missing"}, openai.ToolErrorTypeUnknownFunction},
+		{"missing argument", openai.FunctionCall{Name: "get_weather"}, openai.ToolErrorTypeInvalidArguments},
+		{"invalid JSON", openai.FunctionCall{Name: "get_weather", Arguments: `{`}, openai.ToolErrorTypeInvalidArguments},
+		{
+			"handler error",
+			openai.FunctionCall{Name: "get_weather", Arguments: `{"city":"Atlantis"}`},
+			openai.ToolErrorTypeExecution,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			message := registry.Dispatch(ctx, openai.ToolCall{Function: tc.toolCall})
+			var toolErr openai.ToolCallError
+			checks.NoError(t, json.Unmarshal([]byte(message.Content), &toolErr), "expected a ToolCallError")
+			if toolErr.Type != tc.errType || toolErr.Message == "" {
+				t.Errorf("unexpected error: %+v", toolErr)
+			}
+		})
+	}
+}
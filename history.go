@@ -0,0 +1,307 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ibanyu/go-openai/tokenizer"
+)
+
+const (
+	defaultSummaryPrompt = "Summarize the following conversation in a few sentences, keeping every fact, " +
+		"decision and open question needed to continue it."
+	defaultMaxSummaryTokens = 500
+	// summaryMessageName names the message holding a summary, which is
+	// summarized again instead of being kept as a system message.
+	summaryMessageName = "conversation_summary"
+)
+
+var (
+	ErrContextWindowExceeded   = errors.New("messages do not fit in the context window")
+	ErrHistoryNoTokenCounter   = errors.New("history manager has no token counter")
+	ErrHistorySummaryNoChoices = errors.New("history summary response has no choices")
+)
+
+// MessageGroup is a run of messages that are kept or dropped together: a
+// single message, or an assistant message with tool calls followed by the
+// tool messages answering them.
+type MessageGroup struct {
+	Messages []ChatCompletionMessage
+	// Tokens is the number of prompt tokens of Messages.
+	Tokens int
+	// Pinned is set for system and developer messages, which must be kept.
+	Pinned bool
+}
+
+// HistoryStrategy chooses the messages sent when a conversation does not fit
+// in the context window. HistoryManager.Fit only calls it in that case.
+type HistoryStrategy interface {
+	// Trim returns the groups to send, in order, whose tokens add up to at
+	// most budget. Pinned groups must be kept. count counts the tokens of the
+	// messages a strategy adds, such as summaries.
+	Trim(
+		ctx context.Context,
+		groups []MessageGroup,
+		budget int,
+		count func(ChatCompletionMessage) int,
+	) ([]MessageGroup, error)
+}
+
+// HistoryManager trims conversation histories to fit in the context window of
+// a model.
+type HistoryManager struct {
+	// ContextWindow is the number of tokens of the context window of the
+	// model, such as ModelCapabilities.ContextWindow.
+	ContextWindow int
+	// CompletionTokens is the number of tokens kept for the completion.
+	CompletionTokens int
+	// ReservedTokens is the number of tokens kept for the rest of the request,
+	// such as tool definitions.
+	ReservedTokens int
+	// Encoding counts the tokens of messages with CountChatMessageTokens.
+	Encoding *tokenizer.Encoding
+	// CountTokens, when set, counts the tokens of messages instead of Encoding.
+	CountTokens func(message ChatCompletionMessage) int
+	// Strategy trims the history. Defaults to DropOldestStrategy.
+	Strategy HistoryStrategy
+}
+
+// Fit returns messages, trimmed by the strategy of the manager when they do
+// not fit in the context window. An assistant message with tool calls is
+// never separated from the tool messages answering it.
+func (m *HistoryManager) Fit(ctx context.Context, messages []ChatCompletionMessage) ([]ChatCompletionMessage, error) {
+	count := m.CountTokens
+	if count == nil {
+		if m.Encoding == nil {
+			return nil, ErrHistoryNoTokenCounter
+		}
+		count = func(message ChatCompletionMessage) int {
+			return CountChatMessageTokens(m.Encoding, message)
+		}
+	}
+
+	budget := m.ContextWindow - m.CompletionTokens - m.ReservedTokens - tokensPerReplyPriming
+	groups := groupMessages(messages, count)
+	if groupTokens(groups) <= budget {
+		return messages, nil
+	}
+
+	strategy := m.Strategy
+	if strategy == nil {
+		strategy = DropOldestStrategy{}
+	}
+	groups, err := strategy.Trim(ctx, groups, budget, count)
+	if err != nil {
+		return nil, err
+	}
+	if groupTokens(groups) > budget {
+		return nil, ErrContextWindowExceeded
+	}
+
+	var trimmed []ChatCompletionMessage
+	for _, group := range groups {
+		trimmed = append(trimmed, group.Messages...)
+	}
+	return trimmed, nil
+}
+
+// groupMessages splits messages into the groups kept or dropped together.
+func groupMessages(messages []ChatCompletionMessage, count func(ChatCompletionMessage) int) []MessageGroup {
+	var groups []MessageGroup
+	for i := 0; i < len(messages); {
+		start, message := i, messages[i]
+		group := MessageGroup{
+			Messages: messages[start : i+1],
+			Tokens:   count(message),
+			Pinned: (message.Role == ChatMessageRoleSystem || message.Role == ChatMessageRoleDeveloper) &&
+				message.Name != summaryMessageName,
+		}
+		i++
+		if message.Role == ChatMessageRoleAssistant && (len(message.ToolCalls) > 0 || message.FunctionCall != nil) {
+			for i < len(messages) && (messages[i].Role == ChatMessageRoleTool || messages[i].Role == ChatMessageRoleFunction) {
+				group.Messages = messages[start : i+1]
+				group.Tokens += count(messages[i])
+				i++
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+func groupTokens(groups []MessageGroup) int {
+	tokens := 0
+	for _, group := range groups {
+		tokens += group.Tokens
+	}
+	return tokens
+}
+
+// dropOldest drops the oldest groups that are not pinned until the rest fit
+// in budget. It returns the kept groups, the dropped ones and the index in
+// kept where the first dropped group was.
+func dropOldest(groups []MessageGroup, budget int) (kept, dropped []MessageGroup, at int) {
+	excess := groupTokens(groups) - budget
+	for _, group := range groups {
+		if excess > 0 && !group.Pinned {
+			if len(dropped) == 0 {
+				at = len(kept)
+			}
+			excess -= group.Tokens
+			dropped = append(dropped, group)
+			continue
+		}
+		kept = append(kept, group)
+	}
+	return kept, dropped, at
+}
+
+// DropOldestStrategy drops the oldest messages first.
+type DropOldestStrategy struct{}
+
+// Trim implements HistoryStrategy.
+func (DropOldestStrategy) Trim(
+	_ context.Context,
+	groups []MessageGroup,
+	budget int,
+	_ func(ChatCompletionMessage) int,
+) ([]MessageGroup, error) {
+	kept, _, _ := dropOldest(groups, budget)
+	return kept, nil
+}
+
+// SlidingWindowStrategy keeps the most recent messages, at most MaxMessages
+// besides system and developer messages, then drops the oldest of them until
+// they fit.
+type SlidingWindowStrategy struct {
+	// MaxMessages limits the number of messages kept. There is no limit when
+	// it is zero or negative.
+	MaxMessages int
+}
+
+// Trim implements HistoryStrategy.
+func (s SlidingWindowStrategy) Trim(
+	_ context.Context,
+	groups []MessageGroup,
+	budget int,
+	_ func(ChatCompletionMessage) int,
+) ([]MessageGroup, error) {
+	var window []MessageGroup
+	messages, full := 0, false
+	for i := len(groups) - 1; i >= 0; i-- {
+		group := groups[i]
+		if !group.Pinned {
+			full = full || (s.MaxMessages > 0 && messages+len(group.Messages) > s.MaxMessages)
+			if full {
+				continue
+			}
+			messages += len(group.Messages)
+		}
+		window = append([]MessageGroup{group}, window...)
+	}
+	kept, _, _ := dropOldest(window, budget)
+	return kept, nil
+}
+
+// SummarizeStrategy replaces the oldest messages with a summary written by
+// Model. The summary is a system message, which is summarized again with the
+// next messages to drop.
+type SummarizeStrategy struct {
+	Client *Client
+	Model  string
+	// Prompt instructs the model how to summarize the conversation.
+	Prompt string
+	// MaxSummaryTokens limits the length of the summary. Defaults to 500.
+	MaxSummaryTokens int
+}
+
+// Trim implements HistoryStrategy.
+func (s SummarizeStrategy) Trim(
+	ctx context.Context,
+	groups []MessageGroup,
+	budget int,
+	count func(ChatCompletionMessage) int,
+) ([]MessageGroup, error) {
+	maxSummaryTokens := s.MaxSummaryTokens
+	if maxSummaryTokens <= 0 {
+		maxSummaryTokens = defaultMaxSummaryTokens
+	}
+	summaryOverhead := count(ChatCompletionMessage{Role: ChatMessageRoleSystem, Name: summaryMessageName})
+	kept, dropped, at := dropOldest(groups, budget-maxSummaryTokens-summaryOverhead)
+	if len(dropped) == 0 {
+		return kept, nil
+	}
+
+	summary, err := s.summarize(ctx, dropped)
+	if err != nil {
+		return nil, err
+	}
+	message := ChatCompletionMessage{Role: ChatMessageRoleSystem, Name: summaryMessageName, Content: summary}
+	group := MessageGroup{Messages: []ChatCompletionMessage{message}, Tokens: count(message)}
+
+	// The summary takes the place of the first dropped message.
+	result := make([]MessageGroup, 0, len(kept)+1)
+	result = append(append(append(result, kept[:at]...), group), kept[at:]...)
+	if groupTokens(result) > budget {
+		result, _, _ = dropOldest(result, budget)
+	}
+	return result, nil
+}
+
+func (s SummarizeStrategy) summarize(ctx context.Context, groups []MessageGroup) (string, error) {
+	prompt := s.Prompt
+	if prompt == "" {
+		prompt = defaultSummaryPrompt
+	}
+	maxSummaryTokens := s.MaxSummaryTokens
+	if maxSummaryTokens <= 0 {
+		maxSummaryTokens = defaultMaxSummaryTokens
+	}
+
+	var transcript strings.Builder
+	for _, group := range groups {
+		for _, message := range group.Messages {
+			writeTranscriptMessage(&transcript, message)
+		}
+	}
+	response, err := s.Client.CreateChatCompletion(ctx, ChatCompletionRequest{
+		Model: s.Model,
+		Messages: []ChatCompletionMessage{
+			{Role: ChatMessageRoleSystem, Content: prompt},
+			{Role: ChatMessageRoleUser, Content: transcript.String()},
+		},
+		MaxCompletionTokens: maxSummaryTokens,
+	})
+	if err != nil {
+		return "", fmt.Errorf("summarize history: %w", err)
+	}
+	if len(response.Choices) == 0 {
+		return "", fmt.Errorf("summarize history: %w", ErrHistorySummaryNoChoices)
+	}
+	return response.Choices[0].Message.Content, nil
+}
+
+// writeTranscriptMessage renders message as a line of a plain text transcript.
+func writeTranscriptMessage(b *strings.Builder, message ChatCompletionMessage) {
+	content := message.Content
+	for _, part := range message.MultiContent {
+		if part.Type == ChatMessagePartTypeText {
+			content += part.Text
+		}
+	}
+	if message.Name == summaryMessageName {
+		fmt.Fprintf(b, "summary of the earlier conversation: %s\n", content)
+		return
+	}
+	if content != "" {
+		fmt.Fprintf(b, "%s: %s\n", message.Role, content)
+	}
+	for _, call := range message.ToolCalls {
+		fmt.Fprintf(b, "%s called %s(%s)\n", message.Role, call.Function.Name, call.Function.Arguments)
+	}
+	if call := message.FunctionCall; call != nil {
+		fmt.Fprintf(b, "%s called %s(%s)\n", message.Role, call.Name, call.Arguments)
+	}
+}
@@ -0,0 +1,118 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ibanyu/go-openai"
+	"github.com/ibanyu/go-openai/internal/test/checks"
+)
+
+// historyTokens counts one token per byte of content, so that budgets are
+// easy to follow.
+func historyTokens(message openai.ChatCompletionMessage) int {
+	return len(message.Content)
+}
+
+func historyContents(messages []openai.ChatCompletionMessage) []string {
+	contents := make([]string, len(messages))
+	for i, message := range messages {
+		contents[i] = message.Content
+	}
+	return contents
+}
+
+func historyConversation() []openai.ChatCompletionMessage {
+	return []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleSystem, Content: "sys"},
+		{Role: openai.ChatMessageRoleUser, Content: "aaaa"},
+		{Role: openai.ChatMessageRoleAssistant, Content: "bb", ToolCalls: []openai.ToolCall{
+			{ID: "1", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "f", Arguments: "{}"}},
+		}},
+		{Role: openai.ChatMessageRoleTool, Content: "cccc", ToolCallID: "1"},
+		{Role: openai.ChatMessageRoleAssistant, Content: "dd"},
+		{Role: openai.ChatMessageRoleUser, Content: "ee"},
+	}
+}
+
+func TestHistoryManagerFit(t *testing.T) {
+	tests := []struct {
+		name     string
+		window   int
+		strategy openai.HistoryStrategy
+		want     []string
+	}{
+		{"fits", 3 + 17, nil, []string{"sys", "aaaa", "bb", "cccc", "dd", "ee"}},
+		{"drop oldest", 3 + 13, nil, []string{"sys", "bb", "cccc", "dd", "ee"}},
+		{"keep tool calls with replies", 3 + 12, nil, []string{"sys", "dd", "ee"}},
+		{"sliding window", 3 + 16, openai.SlidingWindowStrategy{MaxMessages: 3}, []string{"sys", "dd", "ee"}},
+		{"sliding window budget", 3 + 5, openai.SlidingWindowStrategy{MaxMessages: 3}, []string{"sys", "ee"}},
+		{"unlimited sliding window", 3 + 13, openai.SlidingWindowStrategy{}, []string{"sys", "bb", "cccc", "dd", "ee"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			manager := openai.HistoryManager{
+				ContextWindow:  tc.window + 10,
+				ReservedTokens: 10,
+				CountTokens:    historyTokens,
+				Strategy:       tc.strategy,
+			}
+			messages, err := manager.Fit(context.Background(), historyConversation())
+			checks.NoError(t, err, "Fit error")
+			if got := historyContents(messages); fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Errorf("unexpected messages: got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestHistoryManagerFitErrors(t *testing.T) {
+	manager := openai.HistoryManager{ContextWindow: 5, CountTokens: historyTokens}
+	_, err := manager.Fit(context.Background(), historyConversation())
+	if !errors.Is(err, openai.ErrContextWindowExceeded) {
+		t.Errorf("expected ErrContextWindowExceeded, got %v", err)
+	}
+
+	manager = openai.HistoryManager{ContextWindow: 100}
+	_, err = manager.Fit(context.Background(), historyConversation())
+	if !errors.Is(err, openai.ErrHistoryNoTokenCounter) {
+		t.Errorf("expected ErrHistoryNoTokenCounter, got %v", err)
+	}
+}
+
+func TestHistoryManagerSummarize(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	var request openai.ChatCompletionRequest
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&request), "decode request error")
+		fmt.Fprint(w, `{"id":"1","choices":[{"index":0,"message":{"role":"assistant","content":"sum"}}]}`)
+	})
+
+	manager := openai.HistoryManager{
+		ContextWindow: 3 + 12,
+		CountTokens:   historyTokens,
+		Strategy:      openai.SummarizeStrategy{Client: client, Model: openai.GPT4oMini, MaxSummaryTokens: 3},
+	}
+	messages, err := manager.Fit(context.Background(), historyConversation())
+	checks.NoError(t, err, "Fit error")
+
+	if got := historyContents(messages); fmt.Sprint(got) != "[sys sum dd ee]" {
+		t.Fatalf("unexpected messages: %q", got)
+	}
+	if messages[1].Role != openai.ChatMessageRoleSystem || messages[1].Name != "conversation_summary" {
+		t.Errorf("unexpected summary message: %+v", messages[1])
+	}
+	if request.Model != openai.GPT4oMini || request.MaxCompletionTokens != 3 || len(request.Messages) != 2 {
+		t.Fatalf("unexpected summary request: %+v", request)
+	}
+	want := "user: aaaa\nassistant: bb\nassistant called f({})\ntool: cccc\n"
+	if request.Messages[1].Content != want {
+		t.Errorf("unexpected transcript: %q", request.Messages[1].Content)
+	}
+}
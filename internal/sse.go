@@ -0,0 +1,193 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+)
+
+const defaultSSEEventType = "message"
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// SSEEvent is a single event of a server-sent events stream.
+type SSEEvent struct {
+	// Event is the event type, "message" when the event has no event field.
+	Event string
+	// Data holds the data fields of the event joined by newlines.
+	Data []byte
+	// ID is the last event ID seen on the stream.
+	ID string
+}
+
+// SSEDecoder decodes a server-sent events stream as described in
+// https://html.spec.whatwg.org/multipage/server-sent-events.html#event-stream-interpretation.
+//
+// Lines may end in CRLF, LF or CR. Comments and unknown fields are ignored,
+// and an event that is still pending when the stream ends is dispatched
+// rather than discarded, since some servers omit the final blank line.
+type SSEDecoder struct {
+	// OnLine, when set, is called with every line read from the stream and the
+	// name of its field. Blank lines and comments have an empty field name.
+	// Returning an error stops decoding and returns that error from Next.
+	OnLine func(field string, line []byte) error
+
+	reader      *bufio.Reader
+	started     bool
+	skipLF      bool
+	eventType   string
+	data        bytes.Buffer
+	hasData     bool
+	lastEventID string
+	retry       time.Duration
+}
+
+// NewSSEDecoder returns a decoder reading events from r.
+func NewSSEDecoder(r io.Reader) *SSEDecoder {
+	reader, ok := r.(*bufio.Reader)
+	if !ok {
+		reader = bufio.NewReader(r)
+	}
+	return &SSEDecoder{reader: reader}
+}
+
+// Retry returns the reconnection time last sent by the server, or zero.
+func (d *SSEDecoder) Retry() time.Duration {
+	return d.retry
+}
+
+// LastEventID returns the last event ID sent by the server.
+func (d *SSEDecoder) LastEventID() string {
+	return d.lastEventID
+}
+
+// Next returns the next event of the stream. It returns io.EOF once the
+// stream ends and no event is pending.
+func (d *SSEDecoder) Next() (SSEEvent, error) {
+	for {
+		line, readErr := d.readLine()
+		if readErr != nil && len(line) == 0 {
+			if errors.Is(readErr, io.EOF) && d.data.Len() > 0 {
+				return d.dispatch(), nil
+			}
+			return SSEEvent{}, readErr
+		}
+
+		field, value := parseSSELine(line)
+		if d.OnLine != nil {
+			if err := d.OnLine(field, line); err != nil {
+				return SSEEvent{}, err
+			}
+		}
+
+		if len(line) == 0 {
+			if d.data.Len() > 0 {
+				return d.dispatch(), nil
+			}
+			// An event with an empty data buffer, such as a "data:" keep-alive,
+			// is not dispatched.
+			d.reset()
+			continue
+		}
+		d.processField(field, value)
+	}
+}
+
+func (d *SSEDecoder) processField(field string, value []byte) {
+	switch field {
+	case "event":
+		d.eventType = string(value)
+	case "data":
+		if d.hasData {
+			d.data.WriteByte('\n')
+		}
+		d.data.Write(value)
+		d.hasData = true
+	case "id":
+		if bytes.IndexByte(value, 0) < 0 {
+			d.lastEventID = string(value)
+		}
+	case "retry":
+		if ms, err := strconv.ParseUint(string(value), 10, 63); err == nil {
+			d.retry = time.Duration(ms) * time.Millisecond
+		}
+	}
+}
+
+func (d *SSEDecoder) dispatch() SSEEvent {
+	event := SSEEvent{
+		Event: d.eventType,
+		Data:  append([]byte(nil), d.data.Bytes()...),
+		ID:    d.lastEventID,
+	}
+	if event.Event == "" {
+		event.Event = defaultSSEEventType
+	}
+	d.reset()
+	return event
+}
+
+func (d *SSEDecoder) reset() {
+	d.eventType = ""
+	d.data.Reset()
+	d.hasData = false
+}
+
+// readLine returns the next line without its terminator. It scans the
+// buffered input for CR or LF instead of reading byte by byte. A CR
+// terminator is only followed by an LF check on the next call, so that
+// streams using bare CR line endings do not block waiting for more input.
+func (d *SSEDecoder) readLine() ([]byte, error) {
+	if d.skipLF {
+		d.skipLF = false
+		if next, err := d.reader.Peek(1); err == nil && next[0] == '\n' {
+			_, _ = d.reader.Discard(1)
+		}
+	}
+
+	var line []byte
+	for {
+		if d.reader.Buffered() == 0 {
+			if _, err := d.reader.Peek(1); err != nil {
+				d.stripBOM(&line)
+				return line, err
+			}
+		}
+		buffered, _ := d.reader.Peek(d.reader.Buffered())
+		end := bytes.IndexAny(buffered, "\r\n")
+		if end < 0 {
+			line = append(line, buffered...)
+			_, _ = d.reader.Discard(len(buffered))
+			continue
+		}
+		line = append(line, buffered[:end]...)
+		d.skipLF = buffered[end] == '\r'
+		_, _ = d.reader.Discard(end + 1)
+		d.stripBOM(&line)
+		return line, nil
+	}
+}
+
+func (d *SSEDecoder) stripBOM(line *[]byte) {
+	if d.started {
+		return
+	}
+	d.started = true
+	*line = bytes.TrimPrefix(*line, utf8BOM)
+}
+
+// parseSSELine splits a line into its field name and value. A single space
+// after the colon is not part of the value.
+func parseSSELine(line []byte) (field string, value []byte) {
+	if len(line) == 0 || line[0] == ':' {
+		return "", nil
+	}
+	name, value, found := bytes.Cut(line, []byte(":"))
+	if !found {
+		return string(line), nil
+	}
+	return string(name), bytes.TrimPrefix(value, []byte(" "))
+}
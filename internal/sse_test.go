@@ -0,0 +1,151 @@
+package openai_test
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	utils "github.com/ibanyu/go-openai/internal"
+)
+
+func decodeAll(t *testing.T, decoder *utils.SSEDecoder) []utils.SSEEvent {
+	t.Helper()
+	var events []utils.SSEEvent
+	for {
+		event, err := decoder.Next()
+		if errors.Is(err, io.EOF) {
+			return events
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		events = append(events, event)
+	}
+}
+
+func TestSSEDecoderFields(t *testing.T) {
+	stream := "\xEF\xBB\xBF: keep-alive\r\n" +
+		"event: thread.run.created\r\n" +
+		"id: 1\r\n" +
+		"data:{\"a\":1}\r\n" +
+		"\r\n" +
+		"retry: 1500\n" +
+		"data: first\n" +
+		"data\n" +
+		"data:  third\n" +
+		"unknown: ignored\n" +
+		"\n" +
+		"event: ignored without data\n" +
+		"\n" +
+		"data: cr\r\rdata: last"
+
+	decoder := utils.NewSSEDecoder(strings.NewReader(stream))
+	events := decodeAll(t, decoder)
+	if len(events) != 4 {
+		t.Fatalf("expected 4 events, got %d: %+v", len(events), events)
+	}
+
+	if events[0].Event != "thread.run.created" || events[0].ID != "1" || string(events[0].Data) != `{"a":1}` {
+		t.Errorf("unexpected first event: %+v", events[0])
+	}
+	if events[1].Event != "message" || events[1].ID != "1" || string(events[1].Data) != "first\n\n third" {
+		t.Errorf("unexpected second event: %+v %q", events[1], events[1].Data)
+	}
+	if string(events[2].Data) != "cr" || string(events[3].Data) != "last" {
+		t.Errorf("unexpected bare CR events: %q %q", events[2].Data, events[3].Data)
+	}
+	if decoder.Retry() != 1500*time.Millisecond || decoder.LastEventID() != "1" {
+		t.Errorf("unexpected decoder state: %v %q", decoder.Retry(), decoder.LastEventID())
+	}
+}
+
+func TestSSEDecoderDispatchesPendingEventAtEOF(t *testing.T) {
+	decoder := utils.NewSSEDecoder(strings.NewReader("data: a\n\ndata: b\n"))
+	events := decodeAll(t, decoder)
+	if len(events) != 2 || string(events[1].Data) != "b" {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+}
+
+func TestSSEDecoderSkipsEmptyData(t *testing.T) {
+	stream := "data: a\n\ndata: \n\nevent: ping\ndata:\n\ndata: b\n\ndata: \n"
+	events := decodeAll(t, utils.NewSSEDecoder(strings.NewReader(stream)))
+	if len(events) != 2 || string(events[0].Data) != "a" || string(events[1].Data) != "b" {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	if events[1].Event != "message" {
+		t.Errorf("unexpected event type: %q", events[1].Event)
+	}
+}
+
+func TestSSEDecoderOnLine(t *testing.T) {
+	errStop := errors.New("stop")
+	var fields []string
+	decoder := utils.NewSSEDecoder(strings.NewReader(": comment\nevent: x\ndata: y\n{\n"))
+	decoder.OnLine = func(field string, _ []byte) error {
+		fields = append(fields, field)
+		if field == "{" {
+			return errStop
+		}
+		return nil
+	}
+
+	_, err := decoder.Next()
+	if !errors.Is(err, errStop) {
+		t.Fatalf("expected OnLine error, got %v", err)
+	}
+	if strings.Join(fields, ",") != ",event,data,{" {
+		t.Errorf("unexpected fields: %q", fields)
+	}
+}
+
+func TestSSEDecoderLineEndings(t *testing.T) {
+	long := strings.Repeat("x", 100)
+	stream := "data: " + long + "\r\n\r\ndata: a\r\n\r\ndata: b\r\r"
+	// A small buffer splits lines and CRLF terminators across reads.
+	decoder := utils.NewSSEDecoder(bufio.NewReaderSize(strings.NewReader(stream), 16))
+	events := decodeAll(t, decoder)
+	if len(events) != 3 || string(events[0].Data) != long || string(events[1].Data) != "a" ||
+		string(events[2].Data) != "b" {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+}
+
+func TestSSEDecoderBareCRDoesNotBlock(t *testing.T) {
+	reader, writer := io.Pipe()
+	defer writer.Close()
+	go func() {
+		_, _ = writer.Write([]byte("data: a\r\r"))
+	}()
+
+	done := make(chan utils.SSEEvent, 1)
+	go func() {
+		event, _ := utils.NewSSEDecoder(reader).Next()
+		done <- event
+	}()
+	select {
+	case event := <-done:
+		if string(event.Data) != "a" {
+			t.Errorf("unexpected event: %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("decoder blocked after a bare CR")
+	}
+}
+
+func BenchmarkSSEDecoder(b *testing.B) {
+	chunk := `data: {"id":"1","choices":[{"index":0,"delta":{"content":"hello"}}]}` + "\n\n"
+	stream := strings.Repeat(chunk, 1000)
+	b.SetBytes(int64(len(stream)))
+	for i := 0; i < b.N; i++ {
+		decoder := utils.NewSSEDecoder(strings.NewReader(stream))
+		for {
+			if _, err := decoder.Next(); err != nil {
+				break
+			}
+		}
+	}
+}
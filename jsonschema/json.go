@@ -5,11 +5,13 @@
 package jsonschema
 
 import (
+	"encoding"
 	"encoding/json"
 	"fmt"
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type DataType string
@@ -48,6 +50,34 @@ type Definition struct {
 	AdditionalProperties any `json:"additionalProperties,omitempty"`
 	// Whether the schema is nullable or not.
 	Nullable bool `json:"nullable,omitempty"`
+
+	// Ref references another schema, such as "#/$defs/Name" for one of Defs.
+	Ref string `json:"$ref,omitempty"`
+	// Defs holds schemas referenced by Ref, usually on the root schema.
+	Defs map[string]Definition `json:"$defs,omitempty"`
+	// AnyOf requires a value to match at least one of the schemas.
+	AnyOf []Definition `json:"anyOf,omitempty"`
+	// OneOf requires a value to match exactly one of the schemas.
+	OneOf []Definition `json:"oneOf,omitempty"`
+	// AllOf requires a value to match all of the schemas.
+	AllOf []Definition `json:"allOf,omitempty"`
+	// Const restricts a value to a single constant.
+	Const any `json:"const,omitempty"`
+	// Format is a semantic format of a string, such as "date-time" or "email".
+	Format string `json:"format,omitempty"`
+	// Pattern is a regular expression a string must match.
+	Pattern string `json:"pattern,omitempty"`
+	// Minimum and Maximum are inclusive bounds of a number.
+	Minimum *float64 `json:"minimum,omitempty"`
+	Maximum *float64 `json:"maximum,omitempty"`
+	// MinLength and MaxLength bound the number of characters of a string.
+	MinLength *int `json:"minLength,omitempty"`
+	MaxLength *int `json:"maxLength,omitempty"`
+	// MinItems and MaxItems bound the number of items of an array.
+	MinItems *int `json:"minItems,omitempty"`
+	MaxItems *int `json:"maxItems,omitempty"`
+	// Default is the value assumed when the value is absent.
+	Default any `json:"default,omitempty"`
 }
 
 func (d *Definition) MarshalJSON() ([]byte, error) {
@@ -66,11 +96,64 @@ func (d *Definition) Unmarshal(content string, v any) error {
 	return VerifySchemaAndUnmarshal(*d, []byte(content), v)
 }
 
+// SchemaProvider is implemented by types that describe their own schema.
+// GenerateSchemaForType uses it instead of reflecting on the type.
+type SchemaProvider interface {
+	JSONSchema() Definition
+}
+
+var (
+	timeType           = reflect.TypeOf(time.Time{})
+	rawMessageType     = reflect.TypeOf(json.RawMessage{})
+	schemaProviderType = reflect.TypeOf((*SchemaProvider)(nil)).Elem()
+	textMarshalerType  = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// GenerateSchemaForType generates the schema of the type of v. Struct types
+// that reference themselves are emitted once under the $defs of the returned
+// schema and referenced with $ref, or with "#" for the type of v itself.
 func GenerateSchemaForType(v any) (*Definition, error) {
-	return reflectSchema(reflect.TypeOf(v))
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil, fmt.Errorf("unsupported type: %s", reflect.Invalid)
+	}
+	root := t
+	for root.Kind() == reflect.Ptr {
+		root = root.Elem()
+	}
+	g := &schemaGenerator{
+		root:      root,
+		visiting:  make(map[reflect.Type]bool),
+		recursive: make(map[reflect.Type]bool),
+		names:     make(map[reflect.Type]string),
+		usedNames: make(map[string]bool),
+		defs:      make(map[string]Definition),
+	}
+	d, err := g.reflectSchema(t)
+	if err != nil {
+		return nil, err
+	}
+	if len(g.defs) > 0 {
+		d.Defs = g.defs
+	}
+	return d, nil
+}
+
+// schemaGenerator keeps track of the struct types being generated so that
+// recursive types end up in $defs instead of looping forever.
+type schemaGenerator struct {
+	root      reflect.Type
+	visiting  map[reflect.Type]bool
+	recursive map[reflect.Type]bool
+	names     map[reflect.Type]string
+	usedNames map[string]bool
+	defs      map[string]Definition
 }
 
-func reflectSchema(t reflect.Type) (*Definition, error) {
+func (g *schemaGenerator) reflectSchema(t reflect.Type) (*Definition, error) {
+	if d, ok := specialSchema(t); ok {
+		return d, nil
+	}
 	var d Definition
 	switch t.Kind() {
 	case reflect.String:
@@ -84,59 +167,126 @@ func reflectSchema(t reflect.Type) (*Definition, error) {
 		d.Type = Boolean
 	case reflect.Slice, reflect.Array:
 		d.Type = Array
-		items, err := reflectSchema(t.Elem())
+		items, err := g.reflectSchema(t.Elem())
 		if err != nil {
 			return nil, err
 		}
 		d.Items = items
-	case reflect.Struct:
-		d.Type = Object
-		d.AdditionalProperties = false
-		object, err := reflectSchemaObject(t)
-		if err != nil {
-			return nil, err
+	case reflect.Map:
+		if !isMapKey(t.Key()) {
+			return nil, fmt.Errorf("unsupported map key type: %s", t.Key())
 		}
-		d = *object
-	case reflect.Ptr:
-		definition, err := reflectSchema(t.Elem())
+		values, err := g.reflectSchema(t.Elem())
 		if err != nil {
 			return nil, err
 		}
-		d = *definition
+		d.Type = Object
+		d.AdditionalProperties = values
+	case reflect.Struct:
+		return g.reflectSchemaStruct(t)
+	case reflect.Ptr:
+		return g.reflectSchema(t.Elem())
+	case reflect.Interface:
+		// An interface may hold any value, so its schema is left empty.
 	case reflect.Invalid, reflect.Uintptr, reflect.Complex64, reflect.Complex128,
-		reflect.Chan, reflect.Func, reflect.Interface, reflect.Map,
-		reflect.UnsafePointer:
+		reflect.Chan, reflect.Func, reflect.UnsafePointer:
 		return nil, fmt.Errorf("unsupported type: %s", t.Kind().String())
 	default:
 	}
 	return &d, nil
 }
 
-func reflectSchemaObject(t reflect.Type) (*Definition, error) {
+// specialSchema returns the schema of types that are not encoded the way
+// their kind suggests.
+func specialSchema(t reflect.Type) (*Definition, bool) {
+	switch {
+	case t.Kind() != reflect.Interface && t.Implements(schemaProviderType):
+		var provider SchemaProvider
+		if t.Kind() == reflect.Ptr {
+			provider, _ = reflect.New(t.Elem()).Interface().(SchemaProvider)
+		} else {
+			provider, _ = reflect.Zero(t).Interface().(SchemaProvider)
+		}
+		d := provider.JSONSchema()
+		return &d, true
+	case t.Kind() != reflect.Ptr && reflect.PointerTo(t).Implements(schemaProviderType):
+		provider, _ := reflect.New(t).Interface().(SchemaProvider)
+		d := provider.JSONSchema()
+		return &d, true
+	case t == timeType:
+		return &Definition{Type: String, Format: "date-time"}, true
+	case t == rawMessageType:
+		return &Definition{}, true
+	default:
+		return nil, false
+	}
+}
+
+// isMapKey reports whether encoding/json can encode t as an object key.
+func isMapKey(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	default:
+		return t.Implements(textMarshalerType)
+	}
+}
+
+func (g *schemaGenerator) reflectSchemaStruct(t reflect.Type) (*Definition, error) {
+	if g.visiting[t] && t == g.root {
+		return &Definition{Ref: "#"}, nil
+	}
+	if g.visiting[t] || g.recursive[t] {
+		g.recursive[t] = true
+		return &Definition{Ref: g.ref(t)}, nil
+	}
+
+	g.visiting[t] = true
+	d, err := g.reflectSchemaObject(t)
+	delete(g.visiting, t)
+	if err != nil {
+		return nil, err
+	}
+	if !g.recursive[t] {
+		return d, nil
+	}
+	g.defs[g.names[t]] = *d
+	return &Definition{Ref: g.ref(t)}, nil
+}
+
+// ref returns the reference to the $defs entry of t, named after the type and
+// suffixed with a number when types of different packages share a name.
+func (g *schemaGenerator) ref(t reflect.Type) string {
+	name, ok := g.names[t]
+	if !ok {
+		name = t.Name()
+		for i := 2; g.usedNames[name]; i++ {
+			name = fmt.Sprintf("%s%d", t.Name(), i)
+		}
+		g.names[t] = name
+		g.usedNames[name] = true
+	}
+	return "#/$defs/" + escapePointer(name)
+}
+
+func (g *schemaGenerator) reflectSchemaObject(t reflect.Type) (*Definition, error) {
 	var d = Definition{
 		Type:                 Object,
 		AdditionalProperties: false,
 	}
 	properties := make(map[string]Definition)
 	var requiredFields []string
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
-		if !field.IsExported() {
-			continue
-		}
-		jsonTag := field.Tag.Get("json")
-		var required = true
-		if jsonTag == "" {
-			jsonTag = field.Name
-		} else if strings.HasSuffix(jsonTag, ",omitempty") {
-			jsonTag = strings.TrimSuffix(jsonTag, ",omitempty")
-			required = false
-		}
-
-		item, err := reflectSchema(field.Type)
+	for _, f := range structFields(t) {
+		field := f.field
+		item, err := g.reflectSchema(field.Type)
 		if err != nil {
 			return nil, err
 		}
+		if f.quoted {
+			item = &Definition{Type: String}
+		}
 		description := field.Tag.Get("description")
 		if description != "" {
 			item.Description = description
@@ -150,17 +300,198 @@ func reflectSchemaObject(t reflect.Type) (*Definition, error) {
 			nullable, _ := strconv.ParseBool(n)
 			item.Nullable = nullable
 		}
+		if err = applyConstraintTags(item, field); err != nil {
+			return nil, err
+		}
 
-		properties[jsonTag] = *item
+		properties[f.name] = *item
 
+		required := !f.omitempty
 		if s := field.Tag.Get("required"); s != "" {
 			required, _ = strconv.ParseBool(s)
 		}
 		if required {
-			requiredFields = append(requiredFields, jsonTag)
+			requiredFields = append(requiredFields, f.name)
 		}
 	}
 	d.Required = requiredFields
 	d.Properties = properties
 	return &d, nil
 }
+
+// structField is a field encoded by encoding/json, possibly promoted from an
+// embedded struct.
+type structField struct {
+	field     reflect.StructField
+	name      string
+	tagged    bool
+	omitempty bool
+	quoted    bool
+	depth     int
+}
+
+// structFields lists the fields of t the way encoding/json encodes them:
+// fields tagged "-" and unexported fields are skipped, and the fields of
+// embedded structs are promoted unless a shallower field has the same name.
+func structFields(t reflect.Type) []structField {
+	fields := collectFields(t, 0, map[reflect.Type]bool{})
+
+	byName := make(map[string][]int)
+	for i, f := range fields {
+		byName[f.name] = append(byName[f.name], i)
+	}
+	var result []structField
+	for i, f := range fields {
+		if dominantField(fields, byName[f.name]) == i {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+func collectFields(t reflect.Type, depth int, embedded map[reflect.Type]bool) []structField {
+	embedded[t] = true
+	defer delete(embedded, t)
+
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if !field.IsExported() && !(field.Anonymous && fieldType.Kind() == reflect.Struct) {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			if !embedded[fieldType] {
+				fields = append(fields, collectFields(fieldType, depth+1, embedded)...)
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		f := structField{field: field, name: name, tagged: name != "", depth: depth}
+		if name == "" {
+			f.name = field.Name
+		}
+		for _, option := range strings.Split(options, ",") {
+			switch option {
+			case "omitempty", "omitzero":
+				f.omitempty = true
+			case "string":
+				f.quoted = isQuotable(field.Type)
+			}
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// dominantField returns the index of the field that encoding/json encodes
+// among fields of the same name, or -1 when none wins.
+func dominantField(fields []structField, indexes []int) int {
+	dominant, ambiguous := -1, false
+	for _, i := range indexes {
+		switch {
+		case dominant < 0 || fields[i].depth < fields[dominant].depth:
+			dominant, ambiguous = i, false
+		case fields[i].depth > fields[dominant].depth:
+		case fields[i].tagged && !fields[dominant].tagged:
+			dominant, ambiguous = i, false
+		case fields[i].tagged == fields[dominant].tagged:
+			ambiguous = true
+		}
+	}
+	if ambiguous {
+		return -1
+	}
+	return dominant
+}
+
+// isQuotable reports whether the ",string" tag option applies to t.
+func isQuotable(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	default:
+		return false
+	}
+}
+
+// applyConstraintTags sets the keywords of a field schema given by struct tags
+// named after them: format, pattern, minimum, maximum, minLength, maxLength,
+// minItems, maxItems, default and const.
+func applyConstraintTags(d *Definition, field reflect.StructField) error {
+	d.Format = valueOr(field.Tag.Get("format"), d.Format)
+	d.Pattern = valueOr(field.Tag.Get("pattern"), d.Pattern)
+
+	for tag, target := range map[string]**float64{"minimum": &d.Minimum, "maximum": &d.Maximum} {
+		value, ok := field.Tag.Lookup(tag)
+		if !ok {
+			continue
+		}
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s tag on field %s: %w", tag, field.Name, err)
+		}
+		*target = &number
+	}
+
+	for tag, target := range map[string]**int{
+		"minLength": &d.MinLength,
+		"maxLength": &d.MaxLength,
+		"minItems":  &d.MinItems,
+		"maxItems":  &d.MaxItems,
+	} {
+		value, ok := field.Tag.Lookup(tag)
+		if !ok {
+			continue
+		}
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s tag on field %s: %w", tag, field.Name, err)
+		}
+		*target = &number
+	}
+
+	if value, ok := field.Tag.Lookup("default"); ok {
+		d.Default = parseTagValue(d.Type, value)
+	}
+	if value, ok := field.Tag.Lookup("const"); ok {
+		d.Const = parseTagValue(d.Type, value)
+	}
+	return nil
+}
+
+// parseTagValue decodes a default or const tag. Values of non-string schemas
+// are decoded as JSON, falling back to the raw string.
+func parseTagValue(dataType DataType, value string) any {
+	if dataType == String {
+		return value
+	}
+	var v any
+	if err := json.Unmarshal([]byte(value), &v); err != nil {
+		return value
+	}
+	return v
+}
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
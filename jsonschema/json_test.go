@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/ibanyu/go-openai/jsonschema"
 )
@@ -377,3 +378,179 @@ func structToMap(t *testing.T, v any) map[string]any {
 	}
 	return got
 }
+
+func TestStructToSchemaConstraintTags(t *testing.T) {
+	type Order struct {
+		ID       string   `json:"id" format:"uuid" pattern:"^[a-f0-9-]+$"`
+		Quantity int      `json:"quantity" minimum:"1" maximum:"10" default:"1"`
+		Note     string   `json:"note" minLength:"2" maxLength:"20" default:"none"`
+		Tags     []string `json:"tags" minItems:"1" maxItems:"3"`
+		Kind     string   `json:"kind" const:"order"`
+	}
+
+	schema, err := jsonschema.GenerateSchemaForType(Order{})
+	if err != nil {
+		t.Fatalf("GenerateSchemaForType error: %v", err)
+	}
+	got, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+
+	want := `{
+		"type":"object",
+		"properties":{
+			"id":{"type":"string","format":"uuid","pattern":"^[a-f0-9-]+$"},
+			"quantity":{"type":"integer","minimum":1,"maximum":10,"default":1},
+			"note":{"type":"string","minLength":2,"maxLength":20,"default":"none"},
+			"tags":{"type":"array","items":{"type":"string"},"minItems":1,"maxItems":3},
+			"kind":{"type":"string","const":"order"}
+		},
+		"required":["id","quantity","note","tags","kind"],
+		"additionalProperties":false
+	}`
+	var gotMap, wantMap map[string]any
+	_ = json.Unmarshal(got, &gotMap)
+	_ = json.Unmarshal([]byte(want), &wantMap)
+	if !reflect.DeepEqual(gotMap, wantMap) {
+		t.Errorf("unexpected schema: %s", got)
+	}
+
+	_, err = jsonschema.GenerateSchemaForType(struct {
+		N int `json:"n" minimum:"one"`
+	}{})
+	if err == nil {
+		t.Error("expected an error for an invalid minimum tag")
+	}
+}
+
+type treeNode struct {
+	Value    int         `json:"value"`
+	Children []*treeNode `json:"children,omitempty"`
+}
+
+type linkedList struct {
+	Head *listNode `json:"head"`
+}
+
+type listNode struct {
+	Value string    `json:"value"`
+	Next  *listNode `json:"next" nullable:"true"`
+}
+
+type temperature float64
+
+func (temperature) JSONSchema() jsonschema.Definition {
+	return jsonschema.Definition{Type: jsonschema.Number, Description: "Degrees Celsius"}
+}
+
+type Audit struct {
+	CreatedAt time.Time `json:"created_at"`
+	Version   int       `json:"version"`
+}
+
+func checkGeneratedSchema(t *testing.T, v any, want string) {
+	t.Helper()
+	schema, err := jsonschema.GenerateSchemaForType(v)
+	if err != nil {
+		t.Fatalf("GenerateSchemaForType error: %v", err)
+	}
+	got, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	var gotMap, wantMap map[string]any
+	_ = json.Unmarshal(got, &gotMap)
+	if err = json.Unmarshal([]byte(want), &wantMap); err != nil {
+		t.Fatalf("invalid want: %v", err)
+	}
+	if !reflect.DeepEqual(gotMap, wantMap) {
+		t.Errorf("unexpected schema: %s", got)
+	}
+}
+
+func TestStructToSchemaRecursive(t *testing.T) {
+	checkGeneratedSchema(t, treeNode{}, `{
+		"type":"object",
+		"properties":{
+			"value":{"type":"integer"},
+			"children":{"type":"array","items":{"$ref":"#"}}
+		},
+		"required":["value"],
+		"additionalProperties":false
+	}`)
+
+	checkGeneratedSchema(t, &linkedList{}, `{
+		"type":"object",
+		"properties":{"head":{"$ref":"#/$defs/listNode"}},
+		"required":["head"],
+		"additionalProperties":false,
+		"$defs":{
+			"listNode":{
+				"type":"object",
+				"properties":{
+					"value":{"type":"string"},
+					"next":{"$ref":"#/$defs/listNode","nullable":true}
+				},
+				"required":["value","next"],
+				"additionalProperties":false
+			}
+		}
+	}`)
+
+	schema, err := jsonschema.GenerateSchemaForType(linkedList{})
+	if err != nil {
+		t.Fatalf("GenerateSchemaForType error: %v", err)
+	}
+	var data any
+	_ = json.Unmarshal([]byte(`{"head":{"value":"a","next":{"value":"b","next":{"value":1,"next":null}}}}`), &data)
+	errs := jsonschema.ValidateWithErrors(*schema, data)
+	if len(errs) != 1 || errs[0].Path != "/head/next/next/value" {
+		t.Errorf("unexpected validation errors: %v", errs)
+	}
+}
+
+func TestStructToSchemaFields(t *testing.T) {
+	type base struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	checkGeneratedSchema(t, struct {
+		base
+		*Audit
+		Name     string            `json:"name,omitempty" description:"Shadows base.Name"`
+		Ignored  string            `json:"-"`
+		Dash     string            `json:"-,"`
+		Count    int64             `json:"count,omitempty,string"`
+		Labels   map[string]string `json:"labels"`
+		Scores   map[int]float64   `json:"scores"`
+		Extra    any               `json:"extra"`
+		Raw      json.RawMessage   `json:"raw"`
+		Temp     temperature       `json:"temp"`
+		internal string
+	}{}, `{
+		"type":"object",
+		"properties":{
+			"id":{"type":"string"},
+			"created_at":{"type":"string","format":"date-time"},
+			"version":{"type":"integer"},
+			"name":{"type":"string","description":"Shadows base.Name"},
+			"-":{"type":"string"},
+			"count":{"type":"string"},
+			"labels":{"type":"object","additionalProperties":{"type":"string"}},
+			"scores":{"type":"object","additionalProperties":{"type":"number"}},
+			"extra":{},
+			"raw":{},
+			"temp":{"type":"number","description":"Degrees Celsius"}
+		},
+		"required":["id","created_at","version","-","labels","scores","extra","raw","temp"],
+		"additionalProperties":false
+	}`)
+
+	_, err := jsonschema.GenerateSchemaForType(struct {
+		M map[[2]int]string `json:"m"`
+	}{})
+	if err == nil {
+		t.Error("expected an error for an unsupported map key")
+	}
+}
@@ -0,0 +1,331 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Limits of the strict mode of structured outputs.
+const (
+	maxStrictDepth      = 10
+	maxStrictProperties = 5000
+	maxStrictEnumValues = 1000
+)
+
+// strictKeywords lists the keywords strict mode accepts.
+var strictKeywords = map[string]bool{
+	"$schema": true, "$ref": true, "$defs": true, "definitions": true,
+	"type": true, "title": true, "description": true, "enum": true, "const": true, "anyOf": true,
+	"properties": true, "required": true, "additionalProperties": true, "items": true,
+	"minItems": true, "maxItems": true, "pattern": true, "format": true, "multipleOf": true,
+	"minimum": true, "maximum": true, "exclusiveMinimum": true, "exclusiveMaximum": true,
+}
+
+// strictFormats lists the string formats strict mode accepts.
+var strictFormats = map[string]bool{
+	"date-time": true, "time": true, "date": true, "duration": true, "email": true,
+	"hostname": true, "ipv4": true, "ipv6": true, "uuid": true,
+}
+
+// strictKeywordHints suggest replacements for common unsupported keywords.
+var strictKeywordHints = map[string]string{
+	"nullable": `; use anyOf with {"type":"null"} or MakeStrict instead`,
+	"oneOf":    "; use anyOf instead",
+	"allOf":    "; merge the schemas instead",
+}
+
+// StrictSchemaError describes why a schema is rejected by the strict mode of
+// structured outputs and function calling.
+type StrictSchemaError struct {
+	// Path is the JSON pointer (RFC 6901) of the offending schema, "" for the root.
+	Path string
+	// Message describes the violation and how to fix it.
+	Message string
+}
+
+func (e StrictSchemaError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "(root)"
+	}
+	return path + ": " + e.Message
+}
+
+// StrictSchemaErrors lists every StrictSchemaError found in a schema.
+type StrictSchemaErrors []StrictSchemaError
+
+func (e StrictSchemaErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// CheckStrict checks schema against the rules of strict mode: the root is an
+// object, every object lists all its properties as required and disallows
+// additional properties, only supported keywords are used and the size limits
+// are respected. schema is a Definition or any value that marshals to a JSON
+// schema, such as json.RawMessage. The violations are returned as
+// StrictSchemaErrors.
+func CheckStrict(schema any) error {
+	root, err := decodeSchema(schema)
+	if err != nil {
+		return err
+	}
+	c := &strictChecker{}
+	c.checkRoot(root)
+	if len(c.errs) > 0 {
+		return c.errs
+	}
+	return nil
+}
+
+// MakeStrict rewrites schema to follow the rules of strict mode where this
+// keeps its meaning: optional properties become required properties that
+// accept null, nullable schemas become anyOf unions with null and objects
+// without additionalProperties disallow them. Violations that cannot be
+// rewritten are left for CheckStrict to report.
+func MakeStrict(schema any) (json.RawMessage, error) {
+	root, err := decodeSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(makeStrict(root))
+}
+
+// decodeSchema decodes schema into the values of encoding/json, keeping
+// numbers as json.Number so that they are encoded back unchanged.
+func decodeSchema(schema any) (any, error) {
+	var data []byte
+	switch s := schema.(type) {
+	case json.RawMessage:
+		data = s
+	case []byte:
+		data = s
+	default:
+		var err error
+		if data, err = json.Marshal(schema); err != nil {
+			return nil, err
+		}
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	return v, nil
+}
+
+type strictChecker struct {
+	errs       StrictSchemaErrors
+	properties int
+}
+
+func (c *strictChecker) checkRoot(root any) {
+	schema, _ := root.(map[string]any)
+	if !hasType(schema, Object) {
+		c.addError("", "the root schema must be an object")
+	}
+	if _, ok := schema["anyOf"]; ok {
+		c.addError("", "the root schema must not be anyOf")
+	}
+	c.check(root, "", 0)
+	if c.properties > maxStrictProperties {
+		c.addError("", "the schema has %d properties, more than the %d allowed", c.properties, maxStrictProperties)
+	}
+}
+
+func (c *strictChecker) check(node any, path string, depth int) {
+	schema, ok := node.(map[string]any)
+	if !ok {
+		c.addError(path, "a schema must be an object, got %s", formatValue(node))
+		return
+	}
+	for _, keyword := range sortedKeys(schema) {
+		if !strictKeywords[keyword] {
+			c.addError(path, "keyword %q is not supported%s", keyword, strictKeywordHints[keyword])
+		}
+	}
+	if !hasAny(schema, "type", "$ref", "anyOf", "enum", "const") {
+		c.addError(path, "the schema must have a type")
+	}
+	if format, ok := schema["format"].(string); ok && !strictFormats[format] {
+		c.addError(path, "format %q is not supported", format)
+	}
+	if enum, ok := schema["enum"].([]any); ok && len(enum) > maxStrictEnumValues {
+		c.addError(path, "enum has %d values, more than the %d allowed", len(enum), maxStrictEnumValues)
+	}
+	if isObjectSchema(schema) {
+		depth++
+		c.checkObject(schema, path, depth)
+	}
+	c.checkSubschemas(schema, path, depth)
+}
+
+func (c *strictChecker) checkObject(schema map[string]any, path string, depth int) {
+	if depth == maxStrictDepth+1 {
+		c.addError(path, "objects are nested more than %d levels deep", maxStrictDepth)
+	}
+	if additional, ok := schema["additionalProperties"].(bool); !ok || additional {
+		c.addError(path, "additionalProperties must be false")
+	}
+	properties, _ := schema["properties"].(map[string]any)
+	c.properties += len(properties)
+	required := make(map[string]bool)
+	if names, ok := schema["required"].([]any); ok {
+		for _, name := range names {
+			if s, ok := name.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+	for _, name := range sortedKeys(properties) {
+		if !required[name] {
+			c.addError(path+"/properties/"+escapePointer(name),
+				"property %q must be required; make it accept null to keep it optional", name)
+		}
+	}
+}
+
+func (c *strictChecker) checkSubschemas(schema map[string]any, path string, depth int) {
+	for _, keyword := range []string{"properties", "$defs", "definitions"} {
+		subschemas, _ := schema[keyword].(map[string]any)
+		for _, name := range sortedKeys(subschemas) {
+			c.check(subschemas[name], path+"/"+keyword+"/"+escapePointer(name), depth)
+		}
+	}
+	if items, ok := schema["items"]; ok {
+		c.check(items, path+"/items", depth)
+	}
+	if additional, ok := schema["additionalProperties"].(map[string]any); ok {
+		c.check(additional, path+"/additionalProperties", depth)
+	}
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		for i, subschema := range anyOf {
+			c.check(subschema, fmt.Sprintf("%s/anyOf/%d", path, i), depth)
+		}
+	}
+}
+
+func (c *strictChecker) addError(path, format string, args ...any) {
+	c.errs = append(c.errs, StrictSchemaError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// makeStrict rewrites a decoded schema in place and returns it, or the union
+// that replaces it.
+func makeStrict(node any) any {
+	schema, ok := node.(map[string]any)
+	if !ok {
+		return node
+	}
+	for _, keyword := range []string{"properties", "$defs", "definitions"} {
+		if subschemas, ok := schema[keyword].(map[string]any); ok {
+			for name, subschema := range subschemas {
+				subschemas[name] = makeStrict(subschema)
+			}
+		}
+	}
+	if items, ok := schema["items"]; ok {
+		schema["items"] = makeStrict(items)
+	}
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		for i, subschema := range anyOf {
+			anyOf[i] = makeStrict(subschema)
+		}
+	}
+	if isObjectSchema(schema) {
+		requireProperties(schema)
+	}
+
+	nullable, _ := schema["nullable"].(bool)
+	delete(schema, "nullable")
+	if nullable {
+		return nullableSchema(schema)
+	}
+	return schema
+}
+
+// requireProperties makes every optional property of an object schema
+// required and nullable.
+func requireProperties(schema map[string]any) {
+	if _, ok := schema["additionalProperties"]; !ok {
+		schema["additionalProperties"] = false
+	}
+	properties, _ := schema["properties"].(map[string]any)
+	required, _ := schema["required"].([]any)
+	listed := make(map[string]bool)
+	for _, name := range required {
+		if s, ok := name.(string); ok {
+			listed[s] = true
+		}
+	}
+	for _, name := range sortedKeys(properties) {
+		if !listed[name] {
+			required = append(required, name)
+			properties[name] = nullableSchema(properties[name])
+		}
+	}
+	if len(properties) > 0 {
+		schema["required"] = required
+	}
+}
+
+// nullableSchema returns a schema that accepts null in addition to the values
+// accepted by schema.
+func nullableSchema(node any) any {
+	schema, ok := node.(map[string]any)
+	if !ok || acceptsNull(schema) {
+		return node
+	}
+	if anyOf, ok := schema["anyOf"].([]any); ok && len(schema) == 1 {
+		schema["anyOf"] = append(anyOf, map[string]any{"type": string(Null)})
+		return schema
+	}
+	return map[string]any{"anyOf": []any{schema, map[string]any{"type": string(Null)}}}
+}
+
+func acceptsNull(schema map[string]any) bool {
+	if hasType(schema, Null) {
+		return true
+	}
+	anyOf, _ := schema["anyOf"].([]any)
+	for _, subschema := range anyOf {
+		if s, ok := subschema.(map[string]any); ok && acceptsNull(s) {
+			return true
+		}
+	}
+	return false
+}
+
+func isObjectSchema(schema map[string]any) bool {
+	_, ok := schema["properties"]
+	return ok || hasType(schema, Object)
+}
+
+// hasType reports whether the type of schema is, or includes, dataType.
+func hasType(schema map[string]any, dataType DataType) bool {
+	switch t := schema["type"].(type) {
+	case string:
+		return t == string(dataType)
+	case []any:
+		for _, v := range t {
+			if v == string(dataType) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasAny(schema map[string]any, keywords ...string) bool {
+	for _, keyword := range keywords {
+		if _, ok := schema[keyword]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ibanyu/go-openai/jsonschema"
+)
+
+func TestCheckStrict(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema any
+		want   []jsonschema.StrictSchemaError
+	}{
+		{
+			name: "valid definition",
+			schema: jsonschema.Definition{
+				Type: jsonschema.Object,
+				Properties: map[string]jsonschema.Definition{
+					"name": {Type: jsonschema.String},
+					"tags": {Type: jsonschema.Array, Items: &jsonschema.Definition{Type: jsonschema.String}},
+				},
+				Required:             []string{"name", "tags"},
+				AdditionalProperties: false,
+			},
+		},
+		{
+			name: "invalid definition",
+			schema: &jsonschema.Definition{
+				Type: jsonschema.Object,
+				Properties: map[string]jsonschema.Definition{
+					"name": {Type: jsonschema.String, MinLength: new(int), Nullable: true},
+					"link": {Type: jsonschema.String, Format: "uri"},
+				},
+				Required: []string{"name"},
+			},
+			want: []jsonschema.StrictSchemaError{
+				{Path: "", Message: "additionalProperties must be false"},
+				{Path: "/properties/link", Message: `property "link" must be required; make it accept null to keep it optional`},
+				{Path: "/properties/link", Message: `format "uri" is not supported`},
+				{Path: "/properties/name", Message: `keyword "minLength" is not supported`},
+				{
+					Path:    "/properties/name",
+					Message: `keyword "nullable" is not supported; use anyOf with {"type":"null"} or MakeStrict instead`,
+				},
+			},
+		},
+		{
+			name:   "raw schema",
+			schema: json.RawMessage(`{"anyOf":[{"type":"string"}],"oneOf":[]}`),
+			want: []jsonschema.StrictSchemaError{
+				{Path: "", Message: "the root schema must be an object"},
+				{Path: "", Message: "the root schema must not be anyOf"},
+				{Path: "", Message: `keyword "oneOf" is not supported; use anyOf instead`},
+			},
+		},
+		{
+			name:   "missing type",
+			schema: json.RawMessage(`{"type":"object","properties":{"x":{}},"required":["x"],"additionalProperties":false}`),
+			want:   []jsonschema.StrictSchemaError{{Path: "/properties/x", Message: "the schema must have a type"}},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := jsonschema.CheckStrict(tc.schema)
+			if tc.want == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var errs jsonschema.StrictSchemaErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("expected StrictSchemaErrors, got %v", err)
+			}
+			if !reflect.DeepEqual([]jsonschema.StrictSchemaError(errs), tc.want) {
+				t.Errorf("unexpected errors:\n got %v\nwant %v", errs, tc.want)
+			}
+		})
+	}
+
+	if err := jsonschema.CheckStrict(json.RawMessage(`{`)); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+}
+
+func TestCheckStrictDepth(t *testing.T) {
+	schema := jsonschema.Definition{Type: jsonschema.String}
+	for i := 0; i < 11; i++ {
+		schema = jsonschema.Definition{
+			Type:                 jsonschema.Object,
+			Properties:           map[string]jsonschema.Definition{"child": schema},
+			Required:             []string{"child"},
+			AdditionalProperties: false,
+		}
+	}
+	var errs jsonschema.StrictSchemaErrors
+	if !errors.As(jsonschema.CheckStrict(schema), &errs) || len(errs) != 1 ||
+		errs[0].Message != "objects are nested more than 10 levels deep" {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+}
+
+func TestMakeStrict(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+		Zip  string `json:"zip,omitempty"`
+	}
+	type Person struct {
+		Name    string   `json:"name"`
+		Age     int      `json:"age,omitempty" minimum:"0"`
+		Email   *string  `json:"email" nullable:"true"`
+		Address *Address `json:"address,omitempty"`
+	}
+	schema, err := jsonschema.GenerateSchemaForType(Person{})
+	if err != nil {
+		t.Fatalf("GenerateSchemaForType error: %v", err)
+	}
+
+	strict, err := jsonschema.MakeStrict(schema)
+	if err != nil {
+		t.Fatalf("MakeStrict error: %v", err)
+	}
+	if err = jsonschema.CheckStrict(strict); err != nil {
+		t.Errorf("expected a strict schema, got %v", err)
+	}
+
+	want := `{
+		"type":"object",
+		"properties":{
+			"name":{"type":"string"},
+			"age":{"anyOf":[{"type":"integer","minimum":0},{"type":"null"}]},
+			"email":{"anyOf":[{"type":"string"},{"type":"null"}]},
+			"address":{"anyOf":[{
+				"type":"object",
+				"properties":{"city":{"type":"string"},"zip":{"anyOf":[{"type":"string"},{"type":"null"}]}},
+				"required":["city","zip"],
+				"additionalProperties":false
+			},{"type":"null"}]}
+		},
+		"required":["name","email","address","age"],
+		"additionalProperties":false
+	}`
+	var gotMap, wantMap map[string]any
+	_ = json.Unmarshal(strict, &gotMap)
+	_ = json.Unmarshal([]byte(want), &wantMap)
+	if !reflect.DeepEqual(gotMap, wantMap) {
+		t.Errorf("unexpected schema: %s", strict)
+	}
+
+	var definition jsonschema.Definition
+	if err = json.Unmarshal(strict, &definition); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	var data any
+	_ = json.Unmarshal([]byte(`{"name":"Ann","age":null,"email":null,"address":{"city":"Oslo","zip":null}}`), &data)
+	if errs := jsonschema.ValidateWithErrors(definition, data); len(errs) > 0 {
+		t.Errorf("unexpected validation errors: %v", errs)
+	}
+}
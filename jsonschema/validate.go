@@ -2,27 +2,176 @@ package jsonschema
 
 import (
 	"encoding/json"
-	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
 )
 
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// ValidationError describes a value that does not match its schema.
+type ValidationError struct {
+	// Path is the JSON pointer (RFC 6901) of the invalid value, "" for the root.
+	Path string
+	// Message describes why the value is invalid.
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "(root)"
+	}
+	return path + ": " + e.Message
+}
+
+// ValidationErrors lists every ValidationError found in a value.
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
 func VerifySchemaAndUnmarshal(schema Definition, content []byte, v any) error {
 	var data any
 	err := json.Unmarshal(content, &data)
 	if err != nil {
 		return err
 	}
-	if !Validate(schema, data) {
-		return errors.New("data validation failed against the provided schema")
+	if errs := ValidateWithErrors(schema, data); len(errs) > 0 {
+		return fmt.Errorf("data validation failed against the provided schema: %w", errs)
 	}
 	return json.Unmarshal(content, &v)
 }
 
 func Validate(schema Definition, data any) bool {
-	switch schema.Type {
+	return len(ValidateWithErrors(schema, data)) == 0
+}
+
+// ValidateWithErrors validates data, as decoded by encoding/json, against
+// schema and returns every error found, or nil when data is valid.
+//
+// A schema without a type, such as one only made of a $ref, of combinators or
+// of a description, accepts values of any type, as in JSON Schema.
+func ValidateWithErrors(schema Definition, data any) ValidationErrors {
+	v := &validator{root: schema}
+	v.validate(schema, data, "", 0)
+	return v.errs
+}
+
+// maxRefDepth bounds the number of $ref followed without descending into the
+// value, which stops schemas that reference themselves in a loop.
+const maxRefDepth = 32
+
+type validator struct {
+	root Definition
+	errs ValidationErrors
+}
+
+func (v *validator) validate(schema Definition, data any, path string, refDepth int) {
+	if data == nil && schema.Nullable {
+		return
+	}
+	if schema.Ref != "" {
+		v.validateRef(schema.Ref, data, path, refDepth)
+	}
+	if !validateType(schema.Type, data) {
+		v.addError(path, "expected %s, got %s", schema.Type, typeOf(data))
+		return
+	}
+	if len(schema.Enum) > 0 && !validateEnum(schema.Enum, data) {
+		v.addError(path, "value %s is not one of %q", formatValue(data), schema.Enum)
+	}
+	if schema.Const != nil && formatValue(schema.Const) != formatValue(data) {
+		v.addError(path, "value %s is not %s", formatValue(data), formatValue(schema.Const))
+	}
+	v.validateCombinators(schema, data, path, refDepth)
+
+	switch value := data.(type) {
+	case map[string]any:
+		v.validateObject(schema, value, path)
+	case []any:
+		v.validateArray(schema, value, path)
+	case string:
+		v.validateString(schema, value, path)
+	case float64:
+		v.validateNumber(schema, value, path)
+	case int:
+		v.validateNumber(schema, float64(value), path)
+	}
+}
+
+func (v *validator) validateRef(ref string, data any, path string, refDepth int) {
+	if refDepth >= maxRefDepth {
+		v.addError(path, "too many nested references to %q", ref)
+		return
+	}
+	schema, ok := v.resolve(ref)
+	if !ok {
+		v.addError(path, "unresolved reference %q", ref)
+		return
+	}
+	v.validate(schema, data, path, refDepth+1)
+}
+
+// resolve supports references to the root schema and to its $defs.
+func (v *validator) resolve(ref string) (Definition, bool) {
+	if ref == "#" {
+		return v.root, true
+	}
+	const defsPrefix = "#/$defs/"
+	if !strings.HasPrefix(ref, defsPrefix) {
+		return Definition{}, false
+	}
+	name := strings.NewReplacer("~1", "/", "~0", "~").Replace(strings.TrimPrefix(ref, defsPrefix))
+	schema, ok := v.root.Defs[name]
+	return schema, ok
+}
+
+func (v *validator) validateCombinators(schema Definition, data any, path string, refDepth int) {
+	for _, sub := range schema.AllOf {
+		v.validate(sub, data, path, refDepth)
+	}
+	if len(schema.AnyOf) > 0 && v.countMatches(schema.AnyOf, data, path, refDepth) == 0 {
+		v.addError(path, "value does not match any schema of anyOf")
+	}
+	if len(schema.OneOf) > 0 {
+		if matches := v.countMatches(schema.OneOf, data, path, refDepth); matches != 1 {
+			v.addError(path, "value matches %d schemas of oneOf instead of exactly one", matches)
+		}
+	}
+}
+
+func (v *validator) countMatches(schemas []Definition, data any, path string, refDepth int) int {
+	matches := 0
+	for _, sub := range schemas {
+		subValidator := &validator{root: v.root}
+		subValidator.validate(sub, data, path, refDepth)
+		if len(subValidator.errs) == 0 {
+			matches++
+		}
+	}
+	return matches
+}
+
+func validateType(dataType DataType, data any) bool {
+	switch dataType {
 	case Object:
-		return validateObject(schema, data)
+		_, ok := data.(map[string]any)
+		return ok
 	case Array:
-		return validateArray(schema, data)
+		_, ok := data.([]any)
+		return ok
 	case String:
 		_, ok := data.(string)
 		return ok
@@ -44,43 +193,193 @@ func Validate(schema Definition, data any) bool {
 		return ok
 	case Null:
 		return data == nil
+	case "":
+		// A schema without a type accepts any value.
+		return true
 	default:
 		return false
 	}
 }
 
-func validateObject(schema Definition, data any) bool {
-	dataMap, ok := data.(map[string]any)
+func validateEnum(enum []string, data any) bool {
+	value, ok := data.(string)
 	if !ok {
-		return false
+		value = formatValue(data)
 	}
+	return contains(enum, value)
+}
+
+func (v *validator) validateObject(schema Definition, data map[string]any, path string) {
 	for _, field := range schema.Required {
-		if _, exists := dataMap[field]; !exists {
-			return false
+		if _, exists := data[field]; !exists {
+			v.addError(path, "missing required property %q", field)
 		}
 	}
-	for key, valueSchema := range schema.Properties {
-		value, exists := dataMap[key]
-		if exists && !Validate(valueSchema, value) {
-			return false
-		} else if !exists && contains(schema.Required, key) {
-			return false
+
+	for _, key := range sortedKeys(data) {
+		value := data[key]
+		valuePath := path + "/" + escapePointer(key)
+		if valueSchema, ok := schema.Properties[key]; ok {
+			v.validate(valueSchema, value, valuePath, 0)
+			continue
+		}
+		switch additional := schema.AdditionalProperties.(type) {
+		case bool:
+			if !additional {
+				v.addError(valuePath, "additional property %q is not allowed", key)
+			}
+		case Definition:
+			v.validate(additional, value, valuePath, 0)
+		case *Definition:
+			if additional != nil {
+				v.validate(*additional, value, valuePath, 0)
+			}
+		case map[string]any:
+			// Schemas decoded from JSON hold the generic form of the schema.
+			additionalSchema, err := decodeDefinition(additional)
+			if err != nil {
+				v.addError(valuePath, "invalid additionalProperties schema: %v", err)
+				continue
+			}
+			v.validate(additionalSchema, value, valuePath, 0)
 		}
 	}
-	return true
 }
 
-func validateArray(schema Definition, data any) bool {
-	dataArray, ok := data.([]any)
-	if !ok {
-		return false
+func decodeDefinition(schema map[string]any) (Definition, error) {
+	var definition Definition
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return definition, err
 	}
-	for _, item := range dataArray {
-		if !Validate(*schema.Items, item) {
-			return false
+	err = json.Unmarshal(data, &definition)
+	return definition, err
+}
+
+func (v *validator) validateArray(schema Definition, data []any, path string) {
+	if schema.MinItems != nil && len(data) < *schema.MinItems {
+		v.addError(path, "expected at least %d items, got %d", *schema.MinItems, len(data))
+	}
+	if schema.MaxItems != nil && len(data) > *schema.MaxItems {
+		v.addError(path, "expected at most %d items, got %d", *schema.MaxItems, len(data))
+	}
+	if schema.Items == nil {
+		return
+	}
+	for i, item := range data {
+		v.validate(*schema.Items, item, fmt.Sprintf("%s/%d", path, i), 0)
+	}
+}
+
+func (v *validator) validateString(schema Definition, data string, path string) {
+	length := utf8.RuneCountInString(data)
+	if schema.MinLength != nil && length < *schema.MinLength {
+		v.addError(path, "expected at least %d characters, got %d", *schema.MinLength, length)
+	}
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		v.addError(path, "expected at most %d characters, got %d", *schema.MaxLength, length)
+	}
+	if schema.Pattern != "" {
+		pattern, err := regexp.Compile(schema.Pattern)
+		if err != nil {
+			v.addError(path, "invalid pattern %q: %v", schema.Pattern, err)
+		} else if !pattern.MatchString(data) {
+			v.addError(path, "value %q does not match pattern %q", data, schema.Pattern)
 		}
 	}
-	return true
+	if schema.Format != "" && !validateFormat(schema.Format, data) {
+		v.addError(path, "value %q is not a valid %s", data, schema.Format)
+	}
+}
+
+func (v *validator) validateNumber(schema Definition, data float64, path string) {
+	if schema.Minimum != nil && data < *schema.Minimum {
+		v.addError(path, "value %v is less than the minimum %v", data, *schema.Minimum)
+	}
+	if schema.Maximum != nil && data > *schema.Maximum {
+		v.addError(path, "value %v is greater than the maximum %v", data, *schema.Maximum)
+	}
+}
+
+func (v *validator) addError(path, format string, args ...any) {
+	v.errs = append(v.errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// validateFormat checks the common string formats. Unknown formats are
+// annotations only and always valid.
+func validateFormat(format, data string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, data)
+		return err == nil
+	case "date":
+		_, err := time.Parse("2006-01-02", data)
+		return err == nil
+	case "time":
+		_, err := time.Parse(time.RFC3339, "1970-01-01T"+data)
+		return err == nil
+	case "email":
+		address, err := mail.ParseAddress(data)
+		return err == nil && address.Address == data
+	case "uri":
+		u, err := url.Parse(data)
+		return err == nil && u.Scheme != ""
+	case "uuid":
+		return uuidPattern.MatchString(data)
+	case "ipv4":
+		ip := net.ParseIP(data)
+		return ip != nil && ip.To4() != nil && !strings.Contains(data, ":")
+	case "ipv6":
+		return net.ParseIP(data) != nil && strings.Contains(data, ":")
+	default:
+		return true
+	}
+}
+
+func typeOf(data any) string {
+	switch value := data.(type) {
+	case nil:
+		return string(Null)
+	case map[string]any:
+		return string(Object)
+	case []any:
+		return string(Array)
+	case string:
+		return string(String)
+	case bool:
+		return string(Boolean)
+	case float64:
+		if value == float64(int64(value)) {
+			return string(Integer)
+		}
+		return string(Number)
+	case int:
+		return string(Integer)
+	default:
+		return fmt.Sprintf("%T", data)
+	}
+}
+
+func formatValue(data any) string {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Sprint(data)
+	}
+	return string(b)
+}
+
+// escapePointer escapes a property name for use in a JSON pointer.
+func escapePointer(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
 func contains[S ~[]E, E comparable](s S, v E) bool {
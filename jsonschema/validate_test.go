@@ -1,6 +1,9 @@
 package jsonschema_test
 
 import (
+	"encoding/json"
+	"errors"
+	"reflect"
 	"testing"
 
 	"github.com/ibanyu/go-openai/jsonschema"
@@ -162,3 +165,215 @@ func TestUnmarshal(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateWithErrors(t *testing.T) {
+	schema := jsonschema.Definition{
+		Type: jsonschema.Object,
+		Properties: map[string]jsonschema.Definition{
+			"unit":  {Type: jsonschema.String, Enum: []string{"celsius", "fahrenheit"}},
+			"note":  {Type: jsonschema.String, Nullable: true},
+			"tags":  {Type: jsonschema.Array},
+			"a/b":   {Type: jsonschema.Integer},
+			"items": {Type: jsonschema.Array, Items: &jsonschema.Definition{Type: jsonschema.Integer}},
+		},
+		Required:             []string{"unit", "missing"},
+		AdditionalProperties: false,
+	}
+	data := map[string]any{
+		"unit":  "kelvin",
+		"note":  nil,
+		"tags":  []any{1, "a"},
+		"a/b":   "x",
+		"items": []any{1, 2.5},
+		"extra": true,
+	}
+
+	errs := jsonschema.ValidateWithErrors(schema, data)
+	want := map[string]bool{
+		`(root): missing required property "missing"`:                  true,
+		`/a~1b: expected integer, got string`:                          true,
+		`/extra: additional property "extra" is not allowed`:           true,
+		`/items/1: expected integer, got number`:                       true,
+		`/unit: value "kelvin" is not one of ["celsius" "fahrenheit"]`: true,
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("expected %d errors, got %v", len(want), errs)
+	}
+	for _, err := range errs {
+		if !want[err.Error()] {
+			t.Errorf("unexpected error: %q", err.Error())
+		}
+	}
+}
+
+func TestValidateUntypedSchema(t *testing.T) {
+	for _, data := range []any{"a", 1.0, true, nil, []any{1.0}, map[string]any{"a": 1.0}} {
+		if !jsonschema.Validate(jsonschema.Definition{}, data) {
+			t.Errorf("expected %v to be valid against an untyped schema", data)
+		}
+	}
+
+	schema := jsonschema.Definition{
+		Type: jsonschema.Object,
+		Properties: map[string]jsonschema.Definition{
+			"any":  {Description: "Any value"},
+			"enum": {Enum: []string{"a"}},
+		},
+	}
+	if !jsonschema.Validate(schema, map[string]any{"any": []any{"x"}, "enum": "a"}) {
+		t.Error("expected untyped properties to accept any value")
+	}
+	errs := jsonschema.ValidateWithErrors(schema, map[string]any{"enum": "b"})
+	if len(errs) != 1 || errs[0].Path != "/enum" {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+}
+
+func TestValidateAdditionalPropertiesSchema(t *testing.T) {
+	schema := jsonschema.Definition{
+		Type:                 jsonschema.Object,
+		AdditionalProperties: jsonschema.Definition{Type: jsonschema.Integer},
+	}
+	if !jsonschema.Validate(schema, map[string]any{"a": 1.0}) {
+		t.Error("expected integer additional property to be valid")
+	}
+	errs := jsonschema.ValidateWithErrors(schema, map[string]any{"a": "x"})
+	if len(errs) != 1 || errs[0].Path != "/a" {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+}
+
+func TestValidateAdditionalPropertiesRoundTripped(t *testing.T) {
+	generated, err := jsonschema.GenerateSchemaForType(struct {
+		Meta map[string]int `json:"meta"`
+	}{})
+	if err != nil {
+		t.Fatalf("GenerateSchemaForType error: %v", err)
+	}
+	data, err := json.Marshal(generated)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	var schema jsonschema.Definition
+	if err = json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+
+	var value any
+	_ = json.Unmarshal([]byte(`{"meta":{"a":1,"b":"notint"}}`), &value)
+	errs := jsonschema.ValidateWithErrors(schema, value)
+	if len(errs) != 1 || errs[0].Path != "/meta/b" {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+}
+
+func TestVerifySchemaAndUnmarshalReturnsValidationErrors(t *testing.T) {
+	schema := jsonschema.Definition{
+		Type:       jsonschema.Object,
+		Properties: map[string]jsonschema.Definition{"n": {Type: jsonschema.Integer}},
+	}
+	var v struct{ N int }
+	err := jsonschema.VerifySchemaAndUnmarshal(schema, []byte(`{"n":"1"}`), &v)
+	var errs jsonschema.ValidationErrors
+	if !errors.As(err, &errs) || errs[0].Path != "/n" {
+		t.Fatalf("expected ValidationErrors, got %v", err)
+	}
+}
+
+func TestValidateKeywords(t *testing.T) {
+	one, three := 1, 3
+	low, high := 0.0, 10.0
+	node := jsonschema.Definition{
+		Type: jsonschema.Object,
+		Properties: map[string]jsonschema.Definition{
+			"value":    {Type: jsonschema.Integer, Minimum: &low, Maximum: &high},
+			"children": {Type: jsonschema.Array, Items: &jsonschema.Definition{Ref: "#/$defs/node"}},
+		},
+	}
+	schema := jsonschema.Definition{
+		Type: jsonschema.Object,
+		Defs: map[string]jsonschema.Definition{"node": node},
+		Properties: map[string]jsonschema.Definition{
+			"tree":  {Ref: "#/$defs/node"},
+			"code":  {Type: jsonschema.String, Pattern: "^[A-Z]{2}$", MinLength: &one, MaxLength: &three},
+			"email": {Type: jsonschema.String, Format: "email"},
+			"when":  {Type: jsonschema.String, Format: "date-time"},
+			"kind":  {Const: "order"},
+			"tags":  {Type: jsonschema.Array, MinItems: &one, MaxItems: &one},
+			"id": {AnyOf: []jsonschema.Definition{
+				{Type: jsonschema.String},
+				{Type: jsonschema.Integer},
+			}},
+			"count": {OneOf: []jsonschema.Definition{
+				{Type: jsonschema.Integer},
+				{Type: jsonschema.Number},
+			}},
+			"both": {AllOf: []jsonschema.Definition{
+				{Type: jsonschema.String},
+				{MinLength: &three},
+			}},
+		},
+	}
+
+	valid := map[string]any{
+		"tree": map[string]any{"value": 1.0, "children": []any{
+			map[string]any{"value": 2.0},
+		}},
+		"code":  "AB",
+		"email": "a@example.com",
+		"when":  "2024-07-22T20:33:28Z",
+		"kind":  "order",
+		"tags":  []any{"a"},
+		"id":    "x",
+		"count": 1.5,
+		"both":  "abc",
+	}
+	if errs := jsonschema.ValidateWithErrors(schema, valid); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	invalid := map[string]any{
+		"tree": map[string]any{"value": 1.0, "children": []any{
+			map[string]any{"value": 20.0},
+		}},
+		"code":  "abcd",
+		"email": "not an email",
+		"when":  "yesterday",
+		"kind":  "refund",
+		"tags":  []any{},
+		"id":    true,
+		"count": 1.0,
+		"both":  "ab",
+	}
+	paths := map[string]int{}
+	for _, err := range jsonschema.ValidateWithErrors(schema, invalid) {
+		paths[err.Path]++
+	}
+	want := map[string]int{
+		"/tree/children/0/value": 1,
+		"/code":                  2,
+		"/email":                 1,
+		"/when":                  1,
+		"/kind":                  1,
+		"/tags":                  1,
+		"/id":                    1,
+		"/count":                 1,
+		"/both":                  1,
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("unexpected error paths: %v", paths)
+	}
+}
+
+func TestValidateRecursiveRef(t *testing.T) {
+	schema := jsonschema.Definition{
+		Ref:  "#/$defs/loop",
+		Defs: map[string]jsonschema.Definition{"loop": {Ref: "#/$defs/loop"}},
+	}
+	if jsonschema.Validate(schema, 1.0) {
+		t.Error("expected a self-referencing schema to be rejected")
+	}
+	if jsonschema.Validate(jsonschema.Definition{Ref: "#/$defs/missing"}, 1.0) {
+		t.Error("expected an unresolved reference to be rejected")
+	}
+}
@@ -0,0 +1,53 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+)
+
+// HTTPDoerFunc is an adapter to allow the use of ordinary functions as HTTPDoer.
+type HTTPDoerFunc func(req *http.Request) (*http.Response, error)
+
+// Do calls f(req).
+func (f HTTPDoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps the HTTPDoer used to send every request, including streaming
+// requests and each attempt of a retried request.
+type Middleware func(next HTTPDoer) HTTPDoer
+
+// RequestHook is called before a request is sent. Body is the value the request
+// body is encoded from, e.g. ChatCompletionRequest, or nil for requests without a body.
+// Returning an error aborts the request.
+type RequestHook func(ctx context.Context, req *http.Request, body any) error
+
+// ResponseHook is called once a request has completed. V is the decoded response,
+// e.g. *ChatCompletionResponse, or nil for streaming and raw responses whose body is
+// consumed by the caller. Resp is nil when the request failed before a response was received.
+type ResponseHook func(ctx context.Context, resp *http.Response, v Response, err error)
+
+// httpClient returns the configured HTTPClient wrapped by the configured middlewares.
+// The first middleware is the outermost one.
+func (c *Client) httpClient() HTTPDoer {
+	doer := c.config.HTTPClient
+	for i := len(c.config.Middlewares) - 1; i >= 0; i-- {
+		doer = c.config.Middlewares[i](doer)
+	}
+	return doer
+}
+
+func (c *Client) runRequestHooks(req *http.Request, body any) error {
+	for _, hook := range c.config.RequestHooks {
+		if err := hook(req.Context(), req, body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Client) runResponseHooks(req *http.Request, resp *http.Response, v Response, err error) {
+	for _, hook := range c.config.ResponseHooks {
+		hook(req.Context(), resp, v, err)
+	}
+}
@@ -0,0 +1,163 @@
+package openai_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ibanyu/go-openai"
+	"github.com/ibanyu/go-openai/internal/test/checks"
+)
+
+func headerMiddleware(name string, calls *[]string) openai.Middleware {
+	return func(next openai.HTTPDoer) openai.HTTPDoer {
+		return openai.HTTPDoerFunc(func(req *http.Request) (*http.Response, error) {
+			*calls = append(*calls, name)
+			req.Header.Add("X-Middleware", name)
+			return next.Do(req)
+		})
+	}
+}
+
+func TestMiddlewareChainOrder(t *testing.T) {
+	var calls []string
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.Middlewares = []openai.Middleware{
+			headerMiddleware("first", &calls),
+			headerMiddleware("second", &calls),
+		}
+	})
+	defer teardown()
+
+	var received []string
+	server.RegisterHandler("/v1/models", func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Values("X-Middleware")
+		fmt.Fprint(w, `{"data":[]}`)
+	})
+
+	_, err := client.ListModels(context.Background())
+	checks.NoError(t, err, "ListModels error")
+	if len(calls) != 2 || calls[0] != "first" || calls[1] != "second" {
+		t.Fatalf("unexpected middleware order: %v", calls)
+	}
+	if len(received) != 2 || received[0] != "first" || received[1] != "second" {
+		t.Fatalf("middleware headers were not sent: %v", received)
+	}
+}
+
+func TestMiddlewareAppliesToStreams(t *testing.T) {
+	var calls []string
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.Middlewares = []openai.Middleware{headerMiddleware("stream", &calls)}
+	})
+	defer teardown()
+
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Middleware") != "stream" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{
+		Model:    openai.GPT4oMini,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello!"}},
+	})
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+	if len(calls) != 1 {
+		t.Fatalf("expected the middleware to be called once, got %d", len(calls))
+	}
+}
+
+func TestRequestAndResponseHooks(t *testing.T) {
+	var (
+		seenRequest  openai.ChatCompletionRequest
+		seenResponse *openai.ChatCompletionResponse
+		seenStatus   int
+	)
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.RequestHooks = []openai.RequestHook{
+			func(_ context.Context, req *http.Request, body any) error {
+				seenRequest, _ = body.(openai.ChatCompletionRequest)
+				req.Header.Set("X-Request-Hook", "1")
+				return nil
+			},
+		}
+		config.ResponseHooks = []openai.ResponseHook{
+			func(_ context.Context, resp *http.Response, v openai.Response, err error) {
+				checks.NoError(t, err, "unexpected error in response hook")
+				seenResponse, _ = v.(*openai.ChatCompletionResponse)
+				seenStatus = resp.StatusCode
+			},
+		}
+	})
+	defer teardown()
+
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Request-Hook") != "1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `{"id":"chatcmpl-1","choices":[{"index":0,"message":{"role":"assistant","content":"hi"}}]}`)
+	})
+
+	_, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model:    openai.GPT4oMini,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello!"}},
+	})
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if seenRequest.Model != openai.GPT4oMini {
+		t.Fatalf("request hook did not see the request: %+v", seenRequest)
+	}
+	if seenResponse == nil || seenResponse.ID != "chatcmpl-1" || seenStatus != http.StatusOK {
+		t.Fatalf("response hook did not see the response: %+v", seenResponse)
+	}
+}
+
+func TestRequestHookErrorAbortsRequest(t *testing.T) {
+	errHook := errors.New("hook failed")
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.RequestHooks = []openai.RequestHook{
+			func(context.Context, *http.Request, any) error { return errHook },
+		}
+	})
+	defer teardown()
+
+	requests := 0
+	server.RegisterHandler("/v1/models", func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		fmt.Fprint(w, `{"data":[]}`)
+	})
+
+	_, err := client.ListModels(context.Background())
+	checks.ErrorIs(t, err, errHook, "ListModels should return the hook error")
+	if requests != 0 {
+		t.Fatalf("request should not reach the server")
+	}
+}
+
+func TestResponseHookSeesAPIError(t *testing.T) {
+	var hookErr error
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.ResponseHooks = []openai.ResponseHook{
+			func(_ context.Context, _ *http.Response, _ openai.Response, err error) { hookErr = err },
+		}
+	})
+	defer teardown()
+
+	server.RegisterHandler("/v1/models", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error":{"message":"bad request"}}`)
+	})
+
+	_, err := client.ListModels(context.Background())
+	var apiErr *openai.APIError
+	if !errors.As(hookErr, &apiErr) || !errors.Is(err, hookErr) {
+		t.Fatalf("response hook did not see the APIError: %v", hookErr)
+	}
+}
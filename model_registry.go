@@ -0,0 +1,347 @@
+package openai
+
+import (
+	"strings"
+	"sync"
+)
+
+// ModelEndpoint is an API endpoint serving models.
+type ModelEndpoint string
+
+const (
+	ModelEndpointChatCompletions ModelEndpoint = chatCompletionsSuffix
+	ModelEndpointCompletions     ModelEndpoint = "/completions"
+)
+
+// ModelParam is the JSON name of a chat completion request parameter.
+type ModelParam string
+
+const (
+	ModelParamMaxTokens        ModelParam = "max_tokens"
+	ModelParamLogProbs         ModelParam = "logprobs"
+	ModelParamTopLogProbs      ModelParam = "top_logprobs"
+	ModelParamTemperature      ModelParam = "temperature"
+	ModelParamTopP             ModelParam = "top_p"
+	ModelParamN                ModelParam = "n"
+	ModelParamPresencePenalty  ModelParam = "presence_penalty"
+	ModelParamFrequencyPenalty ModelParam = "frequency_penalty"
+	ModelParamLogitBias        ModelParam = "logit_bias"
+)
+
+// reasoningModelUnsupportedParams are rejected by every reasoning model.
+var reasoningModelUnsupportedParams = []ModelParam{
+	ModelParamMaxTokens,
+	ModelParamLogProbs,
+	ModelParamTopLogProbs,
+	ModelParamTemperature,
+	ModelParamTopP,
+	ModelParamN,
+	ModelParamPresencePenalty,
+	ModelParamFrequencyPenalty,
+	ModelParamLogitBias,
+}
+
+// ModelCapabilities describes what a model supports.
+type ModelCapabilities struct {
+	// ContextWindow is the number of input and output tokens, 0 when unknown.
+	ContextWindow int
+	// MaxOutputTokens is the number of output tokens, 0 when unknown.
+	MaxOutputTokens int
+	// Reasoning is set for reasoning models, which take MaxCompletionTokens
+	// and reject the sampling parameters, logprobs and max_tokens.
+	Reasoning bool
+	// DeveloperRole is set for models that take developer messages instead of
+	// system messages.
+	DeveloperRole bool
+	// UnsupportedParams lists parameters the model rejects when they are set
+	// to anything but their default.
+	UnsupportedParams []ModelParam
+	// Tools, Vision and Audio report support for tools and function calling,
+	// image inputs and audio inputs and outputs. They are not validated.
+	Tools  bool
+	Vision bool
+	Audio  bool
+	// Endpoints lists the endpoints serving the model, every endpoint when empty.
+	Endpoints []ModelEndpoint
+}
+
+// unsupportedParams returns every parameter rejected by the model.
+func (m ModelCapabilities) unsupportedParams() []ModelParam {
+	if !m.Reasoning {
+		return m.UnsupportedParams
+	}
+	return append(append([]ModelParam(nil), reasoningModelUnsupportedParams...), m.UnsupportedParams...)
+}
+
+func (m ModelCapabilities) supportsEndpoint(endpoint ModelEndpoint) bool {
+	if len(m.Endpoints) == 0 {
+		return true
+	}
+	for _, e := range m.Endpoints {
+		if e == endpoint {
+			return true
+		}
+	}
+	return false
+}
+
+// maxModelAliasDepth bounds the aliases followed by a lookup.
+const maxModelAliasDepth = 8
+
+// ModelRegistry maps model names to their capabilities, which the client
+// uses to validate requests. Models are looked up by exact name, then by
+// the base model of fine-tuned "ft:" models, then by the longest registered
+// family prefix. Requests for unknown models are not validated.
+// A ModelRegistry is safe for concurrent use.
+type ModelRegistry struct {
+	mu       sync.RWMutex
+	models   map[string]ModelCapabilities
+	families map[string]ModelCapabilities
+	aliases  map[string]string
+}
+
+// DefaultModelRegistry is used by clients without ClientConfig.ModelRegistry.
+var DefaultModelRegistry = NewModelRegistry()
+
+// NewModelRegistry creates a registry of the models known to this package.
+func NewModelRegistry() *ModelRegistry {
+	r := &ModelRegistry{
+		models:   make(map[string]ModelCapabilities),
+		families: make(map[string]ModelCapabilities),
+		aliases:  make(map[string]string),
+	}
+	registerBuiltinModels(r)
+	return r
+}
+
+// Register sets the capabilities of the model named model.
+func (r *ModelRegistry) Register(model string, capabilities ModelCapabilities) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.models[model] = capabilities
+}
+
+// RegisterFamily sets the capabilities of the models named prefix or starting
+// with prefix followed by "-", such as the dated snapshots of a model.
+func (r *ModelRegistry) RegisterFamily(prefix string, capabilities ModelCapabilities) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.families[prefix] = capabilities
+}
+
+// RegisterAlias makes alias, such as an Azure deployment name, share the
+// capabilities of model.
+func (r *ModelRegistry) RegisterAlias(alias, model string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.aliases[alias] = model
+}
+
+// Lookup returns the capabilities of model, and whether the model is known.
+func (r *ModelRegistry) Lookup(model string) (ModelCapabilities, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.lookup(model, 0)
+}
+
+func (r *ModelRegistry) lookup(model string, depth int) (ModelCapabilities, bool) {
+	if target, ok := r.aliases[model]; ok && depth < maxModelAliasDepth {
+		return r.lookup(target, depth+1)
+	}
+	if capabilities, ok := r.models[model]; ok {
+		return capabilities, true
+	}
+	// Fine-tuned models are named ft:<base model>:<organization>:<suffix>:<id>.
+	if strings.HasPrefix(model, "ft:") && depth < maxModelAliasDepth {
+		base, _, _ := strings.Cut(strings.TrimPrefix(model, "ft:"), ":")
+		return r.lookup(base, depth+1)
+	}
+
+	var (
+		family       string
+		capabilities ModelCapabilities
+	)
+	for prefix, c := range r.families {
+		if len(prefix) > len(family) && (model == prefix || strings.HasPrefix(model, prefix+"-")) {
+			family, capabilities = prefix, c
+		}
+	}
+	return capabilities, family != ""
+}
+
+// supportsEndpoint reports whether endpoint serves model. Unknown models are
+// assumed to be supported.
+func (r *ModelRegistry) supportsEndpoint(endpoint, model string) bool {
+	capabilities, ok := r.Lookup(model)
+	return !ok || capabilities.supportsEndpoint(ModelEndpoint(endpoint))
+}
+
+func (c *Client) modelRegistry() *ModelRegistry {
+	if c.config.ModelRegistry != nil {
+		return c.config.ModelRegistry
+	}
+	return DefaultModelRegistry
+}
+
+var (
+	chatEndpoints        = []ModelEndpoint{ModelEndpointChatCompletions}
+	completionsEndpoints = []ModelEndpoint{ModelEndpointCompletions}
+)
+
+// builtinModelFamilies are registered with RegisterFamily by NewModelRegistry.
+var builtinModelFamilies = map[string]ModelCapabilities{
+	O1: {
+		ContextWindow: 200000, MaxOutputTokens: 100000, Reasoning: true, DeveloperRole: true, Tools: true, Vision: true,
+		Endpoints: chatEndpoints,
+	},
+	O1Mini: {
+		ContextWindow: 128000, MaxOutputTokens: 65536, Reasoning: true,
+		Endpoints: chatEndpoints,
+	},
+	O1Preview: {
+		ContextWindow: 128000, MaxOutputTokens: 32768, Reasoning: true,
+		Endpoints: chatEndpoints,
+	},
+	"o1-pro": {
+		ContextWindow: 200000, MaxOutputTokens: 100000, Reasoning: true, DeveloperRole: true, Tools: true, Vision: true,
+		Endpoints: chatEndpoints,
+	},
+	"o3": {
+		ContextWindow: 200000, MaxOutputTokens: 100000, Reasoning: true, DeveloperRole: true, Tools: true, Vision: true,
+		Endpoints: chatEndpoints,
+	},
+	O3Mini: {
+		ContextWindow: 200000, MaxOutputTokens: 100000, Reasoning: true, DeveloperRole: true, Tools: true,
+		Endpoints: chatEndpoints,
+	},
+	"o4-mini": {
+		ContextWindow: 200000, MaxOutputTokens: 100000, Reasoning: true, DeveloperRole: true, Tools: true, Vision: true,
+		Endpoints: chatEndpoints,
+	},
+	"gpt-5": {
+		ContextWindow: 400000, MaxOutputTokens: 128000, Reasoning: true, DeveloperRole: true, Tools: true, Vision: true,
+		Endpoints: chatEndpoints,
+	},
+	"gpt-5-chat": {
+		ContextWindow: 128000, MaxOutputTokens: 16384, Vision: true,
+		Endpoints: chatEndpoints,
+	},
+	GPT4Dot1: {
+		ContextWindow: 1047576, MaxOutputTokens: 32768, Tools: true, Vision: true,
+		Endpoints: chatEndpoints,
+	},
+	GPT4Dot5Preview: {
+		ContextWindow: 128000, MaxOutputTokens: 16384, Tools: true, Vision: true,
+		Endpoints: chatEndpoints,
+	},
+	GPT4o: {
+		ContextWindow: 128000, MaxOutputTokens: 16384, Tools: true, Vision: true,
+		Endpoints: chatEndpoints,
+	},
+	GPT4oMini: {
+		ContextWindow: 128000, MaxOutputTokens: 16384, Tools: true, Vision: true,
+		Endpoints: chatEndpoints,
+	},
+	"gpt-4o-audio-preview": {
+		ContextWindow: 128000, MaxOutputTokens: 16384, Tools: true, Audio: true,
+		Endpoints: chatEndpoints,
+	},
+	"gpt-4o-mini-audio-preview": {
+		ContextWindow: 128000, MaxOutputTokens: 16384, Tools: true, Audio: true,
+		Endpoints: chatEndpoints,
+	},
+	GPT4oLatest: {
+		ContextWindow: 128000, MaxOutputTokens: 16384, Vision: true,
+		Endpoints: chatEndpoints,
+	},
+	GPT4Turbo: {
+		ContextWindow: 128000, MaxOutputTokens: 4096, Tools: true, Vision: true,
+		Endpoints: chatEndpoints,
+	},
+	GPT4: {
+		ContextWindow: 8192, MaxOutputTokens: 8192, Tools: true,
+		Endpoints: chatEndpoints,
+	},
+	GPT432K: {
+		ContextWindow: 32768, MaxOutputTokens: 32768, Tools: true,
+		Endpoints: chatEndpoints,
+	},
+	GPT3Dot5Turbo: {
+		ContextWindow: 16385, MaxOutputTokens: 4096, Tools: true,
+		Endpoints: chatEndpoints,
+	},
+	GPT3Dot5Turbo16K: {
+		ContextWindow: 16385, MaxOutputTokens: 4096, Tools: true,
+		Endpoints: chatEndpoints,
+	},
+	GPT3Dot5TurboInstruct: {
+		ContextWindow: 4096, MaxOutputTokens: 4096,
+		Endpoints: completionsEndpoints,
+	},
+}
+
+// builtinModels are registered with Register by NewModelRegistry, along with
+// the legacy completion models.
+var builtinModels = map[string]ModelCapabilities{
+	GPT4o20240513: {
+		ContextWindow: 128000, MaxOutputTokens: 4096, Tools: true, Vision: true,
+		Endpoints: chatEndpoints,
+	},
+	GPT4Turbo0125: {
+		ContextWindow: 128000, MaxOutputTokens: 4096, Tools: true,
+		Endpoints: chatEndpoints,
+	},
+	GPT4Turbo1106: {
+		ContextWindow: 128000, MaxOutputTokens: 4096, Tools: true,
+		Endpoints: chatEndpoints,
+	},
+	GPT4TurboPreview: {
+		ContextWindow: 128000, MaxOutputTokens: 4096, Tools: true,
+		Endpoints: chatEndpoints,
+	},
+	GPT4VisionPreview: {
+		ContextWindow: 128000, MaxOutputTokens: 4096, Vision: true,
+		Endpoints: chatEndpoints,
+	},
+	GPT40314: {
+		ContextWindow: 8192, MaxOutputTokens: 8192,
+		Endpoints: chatEndpoints,
+	},
+	GPT432K0314: {
+		ContextWindow: 32768, MaxOutputTokens: 32768,
+		Endpoints: chatEndpoints,
+	},
+	GPT3Dot5Turbo0301: {
+		ContextWindow: 4096, MaxOutputTokens: 4096,
+		Endpoints: chatEndpoints,
+	},
+	GPT3Dot5Turbo0613: {
+		ContextWindow: 4096, MaxOutputTokens: 4096, Tools: true,
+		Endpoints: chatEndpoints,
+	},
+	GPT3Davinci002: {
+		ContextWindow: 16384, MaxOutputTokens: 16384,
+		Endpoints: completionsEndpoints,
+	},
+	GPT3Babbage002: {
+		ContextWindow: 16384, MaxOutputTokens: 16384,
+		Endpoints: completionsEndpoints,
+	},
+}
+
+func registerBuiltinModels(r *ModelRegistry) {
+	for prefix, capabilities := range builtinModelFamilies {
+		r.RegisterFamily(prefix, capabilities)
+	}
+	for model, capabilities := range builtinModels {
+		r.Register(model, capabilities)
+	}
+	for _, model := range []string{
+		CodexCodeDavinci002, CodexCodeCushman001, CodexCodeDavinci001,
+		GPT3TextDavinci003, GPT3TextDavinci002, GPT3TextCurie001, GPT3TextBabbage001, GPT3TextAda001,
+		GPT3TextDavinci001, GPT3DavinciInstructBeta, GPT3Davinci, GPT3CurieInstructBeta, GPT3Curie,
+		GPT3Curie002, GPT3Ada, GPT3Ada002, GPT3Babbage,
+	} {
+		r.Register(model, ModelCapabilities{Endpoints: completionsEndpoints})
+	}
+}
@@ -0,0 +1,98 @@
+package openai_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ibanyu/go-openai"
+	"github.com/ibanyu/go-openai/internal/test/checks"
+)
+
+func TestModelRegistryLookup(t *testing.T) {
+	registry := openai.NewModelRegistry()
+	registry.Register("my-model", openai.ModelCapabilities{ContextWindow: 1000})
+	registry.RegisterAlias("prod-deployment", "o4-mini-2025-04-16")
+
+	tests := []struct {
+		model         string
+		known         bool
+		reasoning     bool
+		contextWindow int
+	}{
+		{openai.GPT4o20240806, true, false, 128000},
+		{openai.O3Mini20250131, true, true, 200000},
+		{"o4-mini-2025-04-16", true, true, 200000},
+		{"gpt-5-mini", true, true, 400000},
+		{"gpt-5-chat-latest", true, false, 128000},
+		{"ft:gpt-4o-mini-2024-07-18:acme::abc123", true, false, 128000},
+		{"ft:o4-mini-2025-04-16:acme:suffix:abc123", true, true, 200000},
+		{"prod-deployment", true, true, 200000},
+		{"my-model", true, false, 1000},
+		{"gpt-4oops", false, false, 0},
+		{"llama3", false, false, 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.model, func(t *testing.T) {
+			capabilities, ok := registry.Lookup(tc.model)
+			if ok != tc.known || capabilities.Reasoning != tc.reasoning ||
+				capabilities.ContextWindow != tc.contextWindow {
+				t.Errorf("unexpected capabilities %+v, known %v", capabilities, ok)
+			}
+		})
+	}
+}
+
+func TestReasoningValidatorWithRegistry(t *testing.T) {
+	registry := openai.NewModelRegistry()
+	registry.RegisterFamily("acme-think", openai.ModelCapabilities{Reasoning: true})
+	registry.Register("acme-fixed", openai.ModelCapabilities{
+		UnsupportedParams: []openai.ModelParam{openai.ModelParamTemperature},
+	})
+	validator := openai.NewReasoningValidatorWithRegistry(registry)
+
+	tests := []struct {
+		name    string
+		request openai.ChatCompletionRequest
+		err     error
+	}{
+		{"gpt-5 max tokens", openai.ChatCompletionRequest{Model: "gpt-5", MaxTokens: 10},
+			openai.ErrReasoningModelMaxTokensDeprecated},
+		{"fine-tuned o4-mini", openai.ChatCompletionRequest{Model: "ft:o4-mini:acme::1", TopP: 0.5},
+			openai.ErrReasoningModelLimitationsOther},
+		{"custom family", openai.ChatCompletionRequest{Model: "acme-think-v2", LogProbs: true},
+			openai.ErrReasoningModelLimitationsLogprobs},
+		{"custom param", openai.ChatCompletionRequest{Model: "acme-fixed", Temperature: 0.2},
+			openai.ErrModelUnsupportedParameter},
+		{"reasoning defaults", openai.ChatCompletionRequest{Model: "o3", Temperature: 1, MaxCompletionTokens: 10}, nil},
+		{"non reasoning", openai.ChatCompletionRequest{Model: "gpt-4.1", MaxTokens: 10, Temperature: 0.2}, nil},
+		{"unknown", openai.ChatCompletionRequest{Model: "acme-other", MaxTokens: 10}, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validator.Validate(tc.request)
+			if !errors.Is(err, tc.err) || (tc.err == nil && err != nil) {
+				t.Errorf("expected %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestClientModelRegistry(t *testing.T) {
+	registry := openai.NewModelRegistry()
+	registry.Register("legacy-deployment", openai.ModelCapabilities{
+		Endpoints: []openai.ModelEndpoint{openai.ModelEndpointCompletions},
+	})
+	config := openai.DefaultConfig("whatever")
+	config.BaseURL = "http://localhost/v1"
+	config.ModelRegistry = registry
+	client := openai.NewClientWithConfig(config)
+
+	_, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model: "legacy-deployment",
+	})
+	checks.ErrorIs(t, err, openai.ErrChatCompletionInvalidModel, "expected ErrChatCompletionInvalidModel")
+
+	_, err = client.CreateCompletion(context.Background(), openai.CompletionRequest{Model: "o4-mini"})
+	checks.ErrorIs(t, err, openai.ErrCompletionUnsupportedModel, "expected ErrCompletionUnsupportedModel")
+}
@@ -54,6 +54,10 @@ type ModelsList struct {
 // ListModels Lists the currently available models,
 // and provides basic information about each model such as the model id and parent.
 func (c *Client) ListModels(ctx context.Context) (models ModelsList, err error) {
+	if c.config.APIType == APITypeOllama {
+		return c.listOllamaModels(ctx)
+	}
+
 	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL("/models"))
 	if err != nil {
 		return
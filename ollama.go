@@ -0,0 +1,539 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ollama.go translates requests into the native Ollama API.
+// refs: https://github.com/ollama/ollama/blob/main/docs/api.md
+
+const (
+	ollamaChatSuffix   = "/api/chat"
+	ollamaEmbedSuffix  = "/api/embed"
+	ollamaTagsSuffix   = "/api/tags"
+	ollamaPullSuffix   = "/api/pull"
+	ollamaShowSuffix   = "/api/show"
+	ollamaDeleteSuffix = "/api/delete"
+)
+
+var (
+	ErrOllamaUnsupportedImageURL   = errors.New("ollama only supports base64 data URLs for images")
+	ErrOllamaUnsupportedTokenInput = errors.New("ollama does not support token arrays as embedding input, use strings")
+)
+
+// ollamaSuffixes maps OpenAI endpoints to their native Ollama equivalents.
+var ollamaSuffixes = map[string]string{
+	chatCompletionsSuffix: ollamaChatSuffix,
+	"/embeddings":         ollamaEmbedSuffix,
+	"/models":             ollamaTagsSuffix,
+}
+
+type ollamaChatRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Tools    []Tool          `json:"tools,omitempty"`
+	Format   any             `json:"format,omitempty"`
+	Options  map[string]any  `json:"options,omitempty"`
+	// Stream defaults to true in Ollama, so it is always sent.
+	Stream bool `json:"stream"`
+}
+
+type ollamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	Thinking  string           `json:"thinking,omitempty"`
+	Images    []string         `json:"images,omitempty"`
+	ToolCalls []ollamaToolCall `json:"tool_calls,omitempty"`
+	ToolName  string           `json:"tool_name,omitempty"`
+}
+
+type ollamaToolCall struct {
+	Function struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	} `json:"function"`
+}
+
+type ollamaChatResponse struct {
+	Model           string        `json:"model"`
+	CreatedAt       time.Time     `json:"created_at"`
+	Message         ollamaMessage `json:"message"`
+	Done            bool          `json:"done"`
+	DoneReason      string        `json:"done_reason"`
+	PromptEvalCount int           `json:"prompt_eval_count"`
+	EvalCount       int           `json:"eval_count"`
+	Error           string        `json:"error,omitempty"`
+
+	httpHeader
+}
+
+type ollamaEmbedRequest struct {
+	Model      string `json:"model"`
+	Input      any    `json:"input"`
+	Dimensions int    `json:"dimensions,omitempty"`
+}
+
+type ollamaEmbedResponse struct {
+	Model           string      `json:"model"`
+	Embeddings      [][]float32 `json:"embeddings"`
+	PromptEvalCount int         `json:"prompt_eval_count"`
+
+	httpHeader
+}
+
+// OllamaModelsList lists the models available on an Ollama server.
+type OllamaModelsList struct {
+	Models []OllamaModel `json:"models"`
+
+	httpHeader
+}
+
+// OllamaModel describes a model available on an Ollama server.
+type OllamaModel struct {
+	Name       string             `json:"name"`
+	Model      string             `json:"model"`
+	ModifiedAt time.Time          `json:"modified_at"`
+	Size       int64              `json:"size"`
+	Digest     string             `json:"digest"`
+	Details    OllamaModelDetails `json:"details"`
+}
+
+// OllamaModelDetails describes the format and family of an Ollama model.
+type OllamaModelDetails struct {
+	ParentModel       string   `json:"parent_model"`
+	Format            string   `json:"format"`
+	Family            string   `json:"family"`
+	Families          []string `json:"families"`
+	ParameterSize     string   `json:"parameter_size"`
+	QuantizationLevel string   `json:"quantization_level"`
+}
+
+// OllamaPullRequest represents a request to download a model from the Ollama library.
+type OllamaPullRequest struct {
+	Model    string `json:"model"`
+	Insecure bool   `json:"insecure,omitempty"`
+}
+
+// OllamaPullResponse represents the final status of a model download.
+type OllamaPullResponse struct {
+	Status string `json:"status"`
+
+	httpHeader
+}
+
+// OllamaShowRequest represents a request for the details of a model.
+type OllamaShowRequest struct {
+	Model   string `json:"model"`
+	Verbose bool   `json:"verbose,omitempty"`
+}
+
+// OllamaShowResponse represents the details of a model.
+type OllamaShowResponse struct {
+	Modelfile    string             `json:"modelfile"`
+	Parameters   string             `json:"parameters"`
+	Template     string             `json:"template"`
+	Details      OllamaModelDetails `json:"details"`
+	ModelInfo    map[string]any     `json:"model_info"`
+	Capabilities []string           `json:"capabilities"`
+	ModifiedAt   time.Time          `json:"modified_at"`
+
+	httpHeader
+}
+
+type ollamaErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// OllamaPullModel downloads a model from the Ollama library and waits until it is available.
+func (c *Client) OllamaPullModel(
+	ctx context.Context,
+	request OllamaPullRequest,
+) (response OllamaPullResponse, err error) {
+	body := struct {
+		OllamaPullRequest
+		Stream bool `json:"stream"`
+	}{OllamaPullRequest: request}
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(ollamaPullSuffix), withBody(body), withHookBody(request))
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
+
+// OllamaShowModel returns the details of a model, including its modelfile, template and parameters.
+func (c *Client) OllamaShowModel(
+	ctx context.Context,
+	request OllamaShowRequest,
+) (response OllamaShowResponse, err error) {
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(ollamaShowSuffix), withBody(request))
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
+
+// OllamaDeleteModel deletes a model and its data.
+func (c *Client) OllamaDeleteModel(ctx context.Context, model string) error {
+	req, err := c.newRequest(ctx, http.MethodDelete, c.fullURL(ollamaDeleteSuffix),
+		withBody(map[string]string{"model": model}))
+	if err != nil {
+		return err
+	}
+
+	return c.sendRequest(req, nil)
+}
+
+// OllamaListModels lists the models available on the Ollama server with their native details.
+func (c *Client) OllamaListModels(ctx context.Context) (models OllamaModelsList, err error) {
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(ollamaTagsSuffix))
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &models)
+	return
+}
+
+func (c *Client) listOllamaModels(ctx context.Context) (models ModelsList, err error) {
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(ollamaTagsSuffix))
+	if err != nil {
+		return
+	}
+
+	var response OllamaModelsList
+	err = c.sendTranslatedRequest(req, &response, func() Response {
+		models.httpHeader = response.httpHeader
+		for _, model := range response.Models {
+			models.Models = append(models.Models, Model{
+				ID:        model.Name,
+				Object:    "model",
+				OwnedBy:   "library",
+				CreatedAt: model.ModifiedAt.Unix(),
+			})
+		}
+		return &models
+	})
+	return
+}
+
+func (c *Client) createOllamaEmbeddings(
+	ctx context.Context,
+	request EmbeddingRequest,
+) (res EmbeddingResponse, err error) {
+	switch request.Input.(type) {
+	case [][]int, []int:
+		err = ErrOllamaUnsupportedTokenInput
+		return
+	}
+
+	body := ollamaEmbedRequest{
+		Model:      string(request.Model),
+		Input:      request.Input,
+		Dimensions: request.Dimensions,
+	}
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(ollamaEmbedSuffix), withBody(body), withHookBody(request))
+	if err != nil {
+		return
+	}
+
+	var response ollamaEmbedResponse
+	err = c.sendTranslatedRequest(req, &response, func() Response {
+		res = EmbeddingResponse{
+			Object: "list",
+			Model:  EmbeddingModel(response.Model),
+			Usage: Usage{
+				PromptTokens: response.PromptEvalCount,
+				TotalTokens:  response.PromptEvalCount,
+			},
+			httpHeader: response.httpHeader,
+		}
+		for i, embedding := range response.Embeddings {
+			res.Data = append(res.Data, Embedding{Object: "embedding", Embedding: embedding, Index: i})
+		}
+		return &res
+	})
+	return
+}
+
+func (c *Client) createOllamaChatCompletion(
+	ctx context.Context,
+	request ChatCompletionRequest,
+) (response ChatCompletionResponse, err error) {
+	body, err := newOllamaChatRequest(request)
+	if err != nil {
+		return
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(ollamaChatSuffix), withBody(body), withHookBody(request))
+	if err != nil {
+		return
+	}
+
+	var ollamaResp ollamaChatResponse
+	err = c.sendTranslatedRequest(req, &ollamaResp, func() Response {
+		message := ollamaResp.Message.toChatCompletionMessage()
+		response = ChatCompletionResponse{
+			Object:  "chat.completion",
+			Created: ollamaResp.CreatedAt.Unix(),
+			Model:   ollamaResp.Model,
+			Choices: []ChatCompletionChoice{{
+				Message:      message,
+				FinishReason: ollamaFinishReason(ollamaResp.DoneReason, len(message.ToolCalls) > 0),
+			}},
+			Usage:      ollamaResp.usage(),
+			httpHeader: ollamaResp.httpHeader,
+		}
+		return &response
+	})
+	return
+}
+
+// createOllamaChatCompletionStream streams the request from the Ollama chat API. Ollama
+// streams newline delimited JSON objects instead of server-sent events.
+func (c *Client) createOllamaChatCompletionStream(
+	ctx context.Context,
+	request ChatCompletionRequest,
+) (stream *ChatCompletionStream, err error) {
+	body, err := newOllamaChatRequest(request)
+	if err != nil {
+		return
+	}
+	body.Stream = true
+
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(ollamaChatSuffix), withBody(body), withHookBody(request))
+	if err != nil {
+		return
+	}
+
+	resp, err := sendRequestStream[ChatCompletionStreamResponse](c, req)
+	if err != nil {
+		return
+	}
+	resp.lineDelimited = true
+	resp.transform = newOllamaStreamConverter().convert
+	stream = &ChatCompletionStream{
+		streamReader: resp,
+	}
+	return
+}
+
+func newOllamaChatRequest(request ChatCompletionRequest) (ollamaChatRequest, error) {
+	r := ollamaChatRequest{
+		Model:   request.Model,
+		Tools:   newOllamaTools(request),
+		Options: newOllamaOptions(request),
+	}
+	if request.ResponseFormat != nil {
+		switch request.ResponseFormat.Type {
+		case ChatCompletionResponseFormatTypeJSONObject:
+			r.Format = "json"
+		case ChatCompletionResponseFormatTypeJSONSchema:
+			if request.ResponseFormat.JSONSchema != nil {
+				r.Format = request.ResponseFormat.JSONSchema.Schema
+			}
+		case ChatCompletionResponseFormatTypeText:
+		}
+	}
+
+	// Ollama identifies tool results by the name of the tool, which OpenAI
+	// tool messages leave to the call they answer.
+	toolNames := map[string]string{}
+	for _, message := range request.Messages {
+		converted := ollamaMessage{
+			Role:     message.Role,
+			Content:  messageText(message),
+			Thinking: message.ReasoningContent,
+		}
+		switch message.Role {
+		case ChatMessageRoleDeveloper:
+			converted.Role = ChatMessageRoleSystem
+		case ChatMessageRoleTool:
+			converted.ToolName = message.Name
+			if converted.ToolName == "" {
+				converted.ToolName = toolNames[message.ToolCallID]
+			}
+		}
+		for _, call := range message.ToolCalls {
+			toolNames[call.ID] = call.Function.Name
+		}
+		for _, part := range message.MultiContent {
+			if part.Type != ChatMessagePartTypeImageURL || part.ImageURL == nil {
+				continue
+			}
+			image, err := ollamaImage(part.ImageURL.URL)
+			if err != nil {
+				return r, err
+			}
+			converted.Images = append(converted.Images, image)
+		}
+		for _, call := range message.ToolCalls {
+			var toolCall ollamaToolCall
+			toolCall.Function.Name = call.Function.Name
+			toolCall.Function.Arguments = json.RawMessage(call.Function.Arguments)
+			if len(toolCall.Function.Arguments) == 0 {
+				toolCall.Function.Arguments = json.RawMessage("{}")
+			}
+			converted.ToolCalls = append(converted.ToolCalls, toolCall)
+		}
+		r.Messages = append(r.Messages, converted)
+	}
+	return r, nil
+}
+
+// newOllamaTools returns the tools of request, with the deprecated functions
+// converted to tools.
+func newOllamaTools(request ChatCompletionRequest) []Tool {
+	tools := append([]Tool(nil), request.Tools...)
+	for i := range request.Functions {
+		tools = append(tools, Tool{Type: ToolTypeFunction, Function: &request.Functions[i]})
+	}
+	return tools
+}
+
+func newOllamaOptions(request ChatCompletionRequest) map[string]any {
+	options := make(map[string]any)
+	if request.Temperature != 0 {
+		options["temperature"] = request.Temperature
+	}
+	if request.TopP != 0 {
+		options["top_p"] = request.TopP
+	}
+	if request.MaxCompletionTokens != 0 {
+		options["num_predict"] = request.MaxCompletionTokens
+	} else if request.MaxTokens != 0 {
+		options["num_predict"] = request.MaxTokens
+	}
+	if len(request.Stop) > 0 {
+		options["stop"] = request.Stop
+	}
+	if request.Seed != nil {
+		options["seed"] = *request.Seed
+	}
+	if request.PresencePenalty != 0 {
+		options["presence_penalty"] = request.PresencePenalty
+	}
+	if request.FrequencyPenalty != 0 {
+		options["frequency_penalty"] = request.FrequencyPenalty
+	}
+	if len(options) == 0 {
+		return nil
+	}
+	return options
+}
+
+// ollamaImage returns the raw base64 data of a data URL.
+func ollamaImage(url string) (string, error) {
+	if !strings.HasPrefix(url, "data:") {
+		return "", ErrOllamaUnsupportedImageURL
+	}
+	_, data, found := strings.Cut(url, ",")
+	if !found {
+		return "", ErrOllamaUnsupportedImageURL
+	}
+	return data, nil
+}
+
+func (m ollamaMessage) toChatCompletionMessage() ChatCompletionMessage {
+	message := ChatCompletionMessage{
+		Role:             m.Role,
+		Content:          m.Content,
+		ReasoningContent: m.Thinking,
+	}
+	for i, call := range m.ToolCalls {
+		message.ToolCalls = append(message.ToolCalls, call.toToolCall(fmt.Sprintf("call_%d", i)))
+	}
+	return message
+}
+
+func (t ollamaToolCall) toToolCall(id string) ToolCall {
+	return ToolCall{
+		ID:   id,
+		Type: ToolTypeFunction,
+		Function: FunctionCall{
+			Name:      t.Function.Name,
+			Arguments: string(t.Function.Arguments),
+		},
+	}
+}
+
+func (r *ollamaChatResponse) usage() Usage {
+	return Usage{
+		PromptTokens:     r.PromptEvalCount,
+		CompletionTokens: r.EvalCount,
+		TotalTokens:      r.PromptEvalCount + r.EvalCount,
+	}
+}
+
+func ollamaFinishReason(doneReason string, hasToolCalls bool) FinishReason {
+	if hasToolCalls {
+		return FinishReasonToolCalls
+	}
+	switch doneReason {
+	case "length":
+		return FinishReasonLength
+	case "":
+		return ""
+	default:
+		return FinishReasonStop
+	}
+}
+
+// ollamaStreamConverter maps Ollama stream objects into chat completion chunks.
+type ollamaStreamConverter struct {
+	sentRole     bool
+	toolCalls    int
+	hasToolCalls bool
+}
+
+func newOllamaStreamConverter() *ollamaStreamConverter {
+	return &ollamaStreamConverter{}
+}
+
+func (s *ollamaStreamConverter) convert(data []byte) ([]byte, error) {
+	var chunk ollamaChatResponse
+	if err := json.Unmarshal(data, &chunk); err != nil {
+		return nil, err
+	}
+	if chunk.Error != "" {
+		return nil, fmt.Errorf("error, %w", &APIError{Message: chunk.Error})
+	}
+
+	var choice ChatCompletionStreamChoice
+	if !s.sentRole {
+		choice.Delta.Role = chunk.Message.Role
+		s.sentRole = true
+	}
+	choice.Delta.Content = chunk.Message.Content
+	choice.Delta.ReasoningContent = chunk.Message.Thinking
+	for _, call := range chunk.Message.ToolCalls {
+		index := s.toolCalls
+		toolCall := call.toToolCall(fmt.Sprintf("call_%d", index))
+		toolCall.Index = &index
+		choice.Delta.ToolCalls = append(choice.Delta.ToolCalls, toolCall)
+		s.toolCalls++
+		s.hasToolCalls = true
+	}
+
+	response := ChatCompletionStreamResponse{
+		Object:  "chat.completion.chunk",
+		Created: chunk.CreatedAt.Unix(),
+		Model:   chunk.Model,
+	}
+	if chunk.Done {
+		choice.FinishReason = ollamaFinishReason(chunk.DoneReason, s.hasToolCalls)
+		usage := chunk.usage()
+		response.Usage = &usage
+	}
+	response.Choices = []ChatCompletionStreamChoice{choice}
+	return json.Marshal(response)
+}
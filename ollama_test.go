@@ -0,0 +1,355 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ibanyu/go-openai"
+	"github.com/ibanyu/go-openai/internal/test/checks"
+)
+
+func setupOllamaTestServer() (client *openai.Client, mux *http.ServeMux, teardown func()) {
+	return setupOllamaTestServerWithConfig(func(*openai.ClientConfig) {})
+}
+
+// setupOllamaTestServerWithConfig is setupOllamaTestServer with a client
+// configured by configure.
+func setupOllamaTestServerWithConfig(
+	configure func(config *openai.ClientConfig),
+) (client *openai.Client, mux *http.ServeMux, teardown func()) {
+	mux = http.NewServeMux()
+	ts := httptest.NewServer(mux)
+	teardown = ts.Close
+	config := openai.DefaultOllamaConfig(ts.URL)
+	configure(&config)
+	client = openai.NewClientWithConfig(config)
+	return
+}
+
+func TestOllamaChatCompletion(t *testing.T) {
+	client, mux, teardown := setupOllamaTestServer()
+	defer teardown()
+
+	var body map[string]any
+	mux.HandleFunc("/api/chat", func(w http.ResponseWriter, r *http.Request) {
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&body), "decode request error")
+		fmt.Fprint(w, `{
+			"model": "llama3.2", "created_at": "2024-07-22T20:33:28.123648Z",
+			"message": {"role": "assistant", "content": "", "tool_calls": [
+				{"function": {"name": "get_weather", "arguments": {"city": "Paris"}}}
+			]},
+			"done": true, "done_reason": "stop", "prompt_eval_count": 26, "eval_count": 8
+		}`)
+	})
+
+	seed := 42
+	resp, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model:          "llama3.2",
+		MaxTokens:      64,
+		Temperature:    0.5,
+		Seed:           &seed,
+		ResponseFormat: &openai.ChatCompletionResponseFormat{Type: openai.ChatCompletionResponseFormatTypeJSONObject},
+		Functions:      []openai.FunctionDefinition{{Name: "get_weather"}},
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleUser, MultiContent: []openai.ChatMessagePart{
+				{Type: openai.ChatMessagePartTypeText, Text: "What is this?"},
+				{
+					Type:     openai.ChatMessagePartTypeImageURL,
+					ImageURL: &openai.ChatMessageImageURL{URL: "data:image/png;base64,AAAA"},
+				},
+			}},
+		},
+	})
+	checks.NoError(t, err, "CreateChatCompletion error")
+
+	if body["stream"] != false || body["format"] != "json" {
+		t.Errorf("unexpected request: %v", body)
+	}
+	options, _ := body["options"].(map[string]any)
+	if options["num_predict"] != float64(64) || options["temperature"] != 0.5 || options["seed"] != float64(42) {
+		t.Errorf("unexpected options: %v", options)
+	}
+	tools, _ := body["tools"].([]any)
+	if len(tools) != 1 || tools[0].(map[string]any)["function"].(map[string]any)["name"] != "get_weather" {
+		t.Errorf("unexpected tools: %v", body["tools"])
+	}
+	message, _ := body["messages"].([]any)[0].(map[string]any)
+	if message["content"] != "What is this?" || message["images"].([]any)[0] != "AAAA" {
+		t.Errorf("unexpected message: %v", message)
+	}
+
+	choice := resp.Choices[0]
+	if choice.FinishReason != openai.FinishReasonToolCalls || len(choice.Message.ToolCalls) != 1 {
+		t.Fatalf("unexpected choice: %+v", choice)
+	}
+	if choice.Message.ToolCalls[0].Function.Arguments != `{"city": "Paris"}` {
+		t.Errorf("unexpected arguments: %s", choice.Message.ToolCalls[0].Function.Arguments)
+	}
+	if resp.Usage.TotalTokens != 34 || resp.Model != "llama3.2" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
+
+func TestOllamaChatCompletionToolMessages(t *testing.T) {
+	client, mux, teardown := setupOllamaTestServer()
+	defer teardown()
+
+	var body struct {
+		Messages []struct {
+			Role     string `json:"role"`
+			ToolName string `json:"tool_name"`
+		} `json:"messages"`
+	}
+	mux.HandleFunc("/api/chat", func(w http.ResponseWriter, r *http.Request) {
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&body), "decode request error")
+		fmt.Fprint(w, `{"model":"llama3.2","message":{"role":"assistant","content":"Sunny"},"done":true}`)
+	})
+
+	_, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model: "llama3.2",
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleDeveloper, Content: "Be brief."},
+			{Role: openai.ChatMessageRoleUser, Content: "Weather in Paris?"},
+			{Role: openai.ChatMessageRoleAssistant, ToolCalls: []openai.ToolCall{{
+				ID: "call_1", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "get_weather"},
+			}}},
+			{Role: openai.ChatMessageRoleTool, ToolCallID: "call_1", Content: "sunny"},
+		},
+	})
+	checks.NoError(t, err, "CreateChatCompletion error")
+
+	if len(body.Messages) != 4 || body.Messages[0].Role != "system" || body.Messages[3].ToolName != "get_weather" {
+		t.Errorf("unexpected messages: %+v", body.Messages)
+	}
+}
+
+func TestOllamaChatCompletionStream(t *testing.T) {
+	client, mux, teardown := setupOllamaTestServer()
+	defer teardown()
+
+	mux.HandleFunc("/api/chat", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		fmt.Fprintln(w, `{"model":"llama3.2","message":{"role":"assistant","content":"Hel"},"done":false}`)
+		fmt.Fprintln(w, `{"model":"llama3.2","message":{"role":"assistant","content":"lo"},"done":false}`)
+		fmt.Fprintln(w, ``)
+		fmt.Fprintln(w, `{"model":"llama3.2","message":{"role":"assistant","content":""},"done":true,`+
+			`"done_reason":"stop","prompt_eval_count":3,"eval_count":2}`)
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{
+		Model:    "llama3.2",
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello!"}},
+	})
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+
+	var content string
+	var last openai.ChatCompletionStreamResponse
+	for {
+		chunk, recvErr := stream.Recv()
+		if errors.Is(recvErr, io.EOF) {
+			break
+		}
+		checks.NoErrorF(t, recvErr, "stream.Recv error")
+		content += chunk.Choices[0].Delta.Content
+		last = chunk
+	}
+	if content != "Hello" {
+		t.Errorf("unexpected content: %q", content)
+	}
+	if last.Choices[0].FinishReason != openai.FinishReasonStop || last.Usage == nil || last.Usage.TotalTokens != 5 {
+		t.Errorf("unexpected last chunk: %+v", last)
+	}
+}
+
+func TestOllamaChatCompletionStreamError(t *testing.T) {
+	client, mux, teardown := setupOllamaTestServer()
+	defer teardown()
+
+	mux.HandleFunc("/api/chat", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintln(w, `{"error":"model runner has unexpectedly stopped"}`)
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{
+		Model:    "llama3.2",
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello!"}},
+	})
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+
+	_, err = stream.Recv()
+	var apiErr *openai.APIError
+	if !errors.As(err, &apiErr) || apiErr.Message != "model runner has unexpectedly stopped" {
+		t.Fatalf("expected APIError, got %v", err)
+	}
+}
+
+func TestOllamaEmbeddings(t *testing.T) {
+	client, mux, teardown := setupOllamaTestServer()
+	defer teardown()
+
+	mux.HandleFunc("/api/embed", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&body), "decode request error")
+		if body["model"] != "all-minilm" || len(body["input"].([]any)) != 2 {
+			t.Errorf("unexpected request: %v", body)
+		}
+		fmt.Fprint(w, `{"model":"all-minilm","embeddings":[[0.1,0.2],[0.3,0.4]],"prompt_eval_count":8}`)
+	})
+
+	resp, err := client.CreateEmbeddings(context.Background(), openai.EmbeddingRequestStrings{
+		Model: "all-minilm",
+		Input: []string{"a", "b"},
+	})
+	checks.NoError(t, err, "CreateEmbeddings error")
+	if len(resp.Data) != 2 || resp.Data[1].Index != 1 || resp.Data[1].Embedding[0] != 0.3 {
+		t.Errorf("unexpected embeddings: %+v", resp.Data)
+	}
+	if resp.Usage.PromptTokens != 8 {
+		t.Errorf("unexpected usage: %+v", resp.Usage)
+	}
+
+	_, err = client.CreateEmbeddings(context.Background(), openai.EmbeddingRequestTokens{
+		Model: "all-minilm",
+		Input: [][]int{{1, 2}},
+	})
+	checks.ErrorIs(t, err, openai.ErrOllamaUnsupportedTokenInput, "expected ErrOllamaUnsupportedTokenInput")
+}
+
+func TestOllamaListModels(t *testing.T) {
+	client, mux, teardown := setupOllamaTestServer()
+	defer teardown()
+
+	mux.HandleFunc("/api/tags", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"models":[{"name":"llama3.2:latest","modified_at":"2024-07-22T20:33:28Z","size":2019393189,
+			"details":{"family":"llama","parameter_size":"3.2B"}}]}`)
+	})
+
+	models, err := client.ListModels(context.Background())
+	checks.NoError(t, err, "ListModels error")
+	if len(models.Models) != 1 || models.Models[0].ID != "llama3.2:latest" || models.Models[0].CreatedAt != 1721680408 {
+		t.Errorf("unexpected models: %+v", models.Models)
+	}
+
+	native, err := client.OllamaListModels(context.Background())
+	checks.NoError(t, err, "OllamaListModels error")
+	if len(native.Models) != 1 || native.Models[0].Details.ParameterSize != "3.2B" {
+		t.Errorf("unexpected native models: %+v", native.Models)
+	}
+}
+
+func TestOllamaHooksSeeOpenAITypes(t *testing.T) {
+	var (
+		seenRequests  []any
+		seenResponses []openai.Response
+	)
+	client, mux, teardown := setupOllamaTestServerWithConfig(func(config *openai.ClientConfig) {
+		config.RequestHooks = []openai.RequestHook{func(_ context.Context, _ *http.Request, body any) error {
+			seenRequests = append(seenRequests, body)
+			return nil
+		}}
+		config.ResponseHooks = []openai.ResponseHook{
+			func(_ context.Context, _ *http.Response, v openai.Response, _ error) {
+				seenResponses = append(seenResponses, v)
+			},
+		}
+	})
+	defer teardown()
+	mux.HandleFunc("/api/chat", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"model": "llama3.2", "message": {"role": "assistant", "content": "Hi"}, "done": true}`)
+	})
+	mux.HandleFunc("/api/embed", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"model": "all-minilm", "embeddings": [[0.1]]}`)
+	})
+	mux.HandleFunc("/api/tags", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"models": [{"name": "llama3.2:latest"}]}`)
+	})
+
+	_, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model:    "llama3.2",
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello"}},
+	})
+	checks.NoError(t, err, "CreateChatCompletion error")
+	_, err = client.CreateEmbeddings(context.Background(), openai.EmbeddingRequest{Model: "all-minilm", Input: "Hello"})
+	checks.NoError(t, err, "CreateEmbeddings error")
+	_, err = client.ListModels(context.Background())
+	checks.NoError(t, err, "ListModels error")
+	_, err = client.OllamaListModels(context.Background())
+	checks.NoError(t, err, "OllamaListModels error")
+
+	if len(seenRequests) != 4 {
+		t.Fatalf("unexpected hook requests: %v", seenRequests)
+	}
+	if _, ok := seenRequests[0].(openai.ChatCompletionRequest); !ok {
+		t.Errorf("unexpected chat hook request: %T", seenRequests[0])
+	}
+	if _, ok := seenRequests[1].(openai.EmbeddingRequest); !ok {
+		t.Errorf("unexpected embeddings hook request: %T", seenRequests[1])
+	}
+	if len(seenResponses) != 4 {
+		t.Fatalf("unexpected hook responses: %v", seenResponses)
+	}
+	response, ok := seenResponses[0].(*openai.ChatCompletionResponse)
+	if !ok || response.Choices[0].Message.Content != "Hi" {
+		t.Errorf("unexpected chat hook response: %#v", seenResponses[0])
+	}
+	if _, ok := seenResponses[1].(*openai.EmbeddingResponse); !ok {
+		t.Errorf("unexpected embeddings hook response: %T", seenResponses[1])
+	}
+	if _, ok := seenResponses[2].(*openai.ModelsList); !ok {
+		t.Errorf("unexpected models hook response: %T", seenResponses[2])
+	}
+	if _, ok := seenResponses[3].(*openai.OllamaModelsList); !ok {
+		t.Errorf("unexpected native models hook response: %T", seenResponses[3])
+	}
+}
+
+func TestOllamaModelManagement(t *testing.T) {
+	client, mux, teardown := setupOllamaTestServer()
+	defer teardown()
+
+	mux.HandleFunc("/api/pull", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&body), "decode request error")
+		if body["model"] != "llama3.2" || body["stream"] != false {
+			t.Errorf("unexpected pull request: %v", body)
+		}
+		fmt.Fprint(w, `{"status":"success"}`)
+	})
+	mux.HandleFunc("/api/show", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"template":"{{ .Prompt }}","capabilities":["completion","tools"],"details":{"family":"llama"}}`)
+	})
+	mux.HandleFunc("/api/delete", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":"model 'missing' not found"}`)
+	})
+
+	pull, err := client.OllamaPullModel(context.Background(), openai.OllamaPullRequest{Model: "llama3.2"})
+	checks.NoError(t, err, "OllamaPullModel error")
+	if pull.Status != "success" {
+		t.Errorf("unexpected pull response: %+v", pull)
+	}
+
+	show, err := client.OllamaShowModel(context.Background(), openai.OllamaShowRequest{Model: "llama3.2"})
+	checks.NoError(t, err, "OllamaShowModel error")
+	if show.Template != "{{ .Prompt }}" || len(show.Capabilities) != 2 || show.Details.Family != "llama" {
+		t.Errorf("unexpected show response: %+v", show)
+	}
+
+	err = client.OllamaDeleteModel(context.Background(), "missing")
+	var apiErr *openai.APIError
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != http.StatusNotFound ||
+		apiErr.Message != "model 'missing' not found" {
+		t.Fatalf("expected not found APIError, got %v", err)
+	}
+}
@@ -6,12 +6,21 @@ import (
 )
 
 func setupOpenAITestServer() (client *openai.Client, server *test.ServerTest, teardown func()) {
+	return setupOpenAITestServerWithConfig(func(*openai.ClientConfig) {})
+}
+
+// setupOpenAITestServerWithConfig is setupOpenAITestServer with a client
+// configured by configure.
+func setupOpenAITestServerWithConfig(
+	configure func(config *openai.ClientConfig),
+) (client *openai.Client, server *test.ServerTest, teardown func()) {
 	server = test.NewTestServer()
 	ts := server.OpenAITestServer()
 	ts.Start()
 	teardown = ts.Close
 	config := openai.DefaultConfig(test.GetTestToken())
 	config.BaseURL = ts.URL + "/v1"
+	configure(&config)
 	client = openai.NewClientWithConfig(config)
 	return
 }
@@ -0,0 +1,252 @@
+// Package openaiserver serves the OpenAI API over HTTP, delegating requests
+// to services implemented in Go, so that other models can be used by OpenAI
+// clients.
+package openaiserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ibanyu/go-openai"
+)
+
+const (
+	errorTypeInvalidRequest = "invalid_request_error"
+	errorTypeServer         = "server_error"
+)
+
+// ChatCompletionService creates chat completions.
+type ChatCompletionService interface {
+	CreateChatCompletion(
+		ctx context.Context,
+		request openai.ChatCompletionRequest,
+	) (openai.ChatCompletionResponse, error)
+	// CreateChatCompletionStream sends the chunks of the completion with send,
+	// which fails once the client is gone.
+	CreateChatCompletionStream(
+		ctx context.Context,
+		request openai.ChatCompletionRequest,
+		send func(openai.ChatCompletionStreamResponse) error,
+	) error
+}
+
+// CompletionService creates completions.
+type CompletionService interface {
+	CreateCompletion(ctx context.Context, request openai.CompletionRequest) (openai.CompletionResponse, error)
+	// CreateCompletionStream sends the chunks of the completion with send,
+	// which fails once the client is gone.
+	CreateCompletionStream(
+		ctx context.Context,
+		request openai.CompletionRequest,
+		send func(openai.CompletionResponse) error,
+	) error
+}
+
+// EmbeddingService creates embeddings.
+type EmbeddingService interface {
+	CreateEmbeddings(ctx context.Context, request openai.EmbeddingRequest) (openai.EmbeddingResponse, error)
+}
+
+// ModelService lists the available models.
+type ModelService interface {
+	ListModels(ctx context.Context) (openai.ModelsList, error)
+}
+
+// Server routes the endpoints of the OpenAI API to its services. Endpoints
+// whose service is nil respond with 404 Not Found.
+type Server struct {
+	ChatCompletions ChatCompletionService
+	Completions     CompletionService
+	Embeddings      EmbeddingService
+	Models          ModelService
+}
+
+// ServeHTTP implements http.Handler for the paths under /v1.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var handler http.Handler
+	switch r.URL.Path {
+	case "/v1/chat/completions":
+		if s.ChatCompletions != nil {
+			handler = ChatCompletionHandler(s.ChatCompletions)
+		}
+	case "/v1/completions":
+		if s.Completions != nil {
+			handler = CompletionHandler(s.Completions)
+		}
+	case "/v1/embeddings":
+		if s.Embeddings != nil {
+			handler = EmbeddingHandler(s.Embeddings)
+		}
+	case "/v1/models":
+		if s.Models != nil {
+			handler = ModelsHandler(s.Models)
+		}
+	}
+	if handler == nil {
+		WriteError(w, &openai.APIError{
+			Message:        "unknown endpoint " + r.URL.Path,
+			Type:           errorTypeInvalidRequest,
+			HTTPStatusCode: http.StatusNotFound,
+		})
+		return
+	}
+	handler.ServeHTTP(w, r)
+}
+
+// ChatCompletionHandler serves POST /v1/chat/completions with service.
+func ChatCompletionHandler(service ChatCompletionService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request openai.ChatCompletionRequest
+		if !decodeRequest(w, r, &request) {
+			return
+		}
+		if request.Stream {
+			writeStream(w, r, func(send func(any) error) error {
+				return service.CreateChatCompletionStream(r.Context(), request,
+					func(chunk openai.ChatCompletionStreamResponse) error { return send(chunk) })
+			})
+			return
+		}
+		response, err := service.CreateChatCompletion(r.Context(), request)
+		writeResponse(w, response, err)
+	})
+}
+
+// CompletionHandler serves POST /v1/completions with service.
+func CompletionHandler(service CompletionService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request openai.CompletionRequest
+		if !decodeRequest(w, r, &request) {
+			return
+		}
+		if request.Stream {
+			writeStream(w, r, func(send func(any) error) error {
+				return service.CreateCompletionStream(r.Context(), request,
+					func(chunk openai.CompletionResponse) error { return send(chunk) })
+			})
+			return
+		}
+		response, err := service.CreateCompletion(r.Context(), request)
+		writeResponse(w, response, err)
+	})
+}
+
+// EmbeddingHandler serves POST /v1/embeddings with service.
+func EmbeddingHandler(service EmbeddingService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request openai.EmbeddingRequest
+		if !decodeRequest(w, r, &request) {
+			return
+		}
+		response, err := service.CreateEmbeddings(r.Context(), request)
+		writeResponse(w, response, err)
+	})
+}
+
+// ModelsHandler serves GET /v1/models with service.
+func ModelsHandler(service ModelService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeMethodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+		models, err := service.ListModels(r.Context())
+		if err == nil {
+			// The models list has no object field of its own.
+			writeResponse(w, struct {
+				Object string         `json:"object"`
+				Models []openai.Model `json:"data"`
+			}{"list", models.Models}, nil)
+			return
+		}
+		WriteError(w, err)
+	})
+}
+
+// WriteError writes err as an ErrorResponse. An APIError is written as is,
+// with its HTTPStatusCode, or 500 when unset. Other errors are written as a
+// server_error with status 500.
+func WriteError(w http.ResponseWriter, err error) {
+	apiErr := &openai.APIError{}
+	if !errors.As(err, &apiErr) {
+		apiErr = &openai.APIError{Message: err.Error(), Type: errorTypeServer}
+	}
+	status := apiErr.HTTPStatusCode
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+	writeJSON(w, status, openai.ErrorResponse{Error: apiErr})
+}
+
+// decodeRequest decodes the body of a POST request into v, and writes an
+// error response when it cannot.
+func decodeRequest(w http.ResponseWriter, r *http.Request, v any) bool {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, r, http.MethodPost)
+		return false
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		WriteError(w, &openai.APIError{
+			Message:        "invalid request body: " + err.Error(),
+			Type:           errorTypeInvalidRequest,
+			HTTPStatusCode: http.StatusBadRequest,
+		})
+		return false
+	}
+	return true
+}
+
+func writeMethodNotAllowed(w http.ResponseWriter, r *http.Request, allowed string) {
+	w.Header().Set("Allow", allowed)
+	WriteError(w, &openai.APIError{
+		Message:        "method " + r.Method + " is not allowed",
+		Type:           errorTypeInvalidRequest,
+		HTTPStatusCode: http.StatusMethodNotAllowed,
+	})
+}
+
+func writeResponse(w http.ResponseWriter, response any, err error) {
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		status = http.StatusInternalServerError
+		data, _ = json.Marshal(openai.ErrorResponse{Error: &openai.APIError{Message: err.Error(), Type: errorTypeServer}})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(data)
+}
+
+// writeStream writes the chunks sent by stream as server-sent events, then
+// the [DONE] message. An error returned before the first chunk is written as
+// an ErrorResponse, later ones as an error event. Nothing more is written
+// once the client is gone.
+func writeStream(w http.ResponseWriter, r *http.Request, stream func(send func(any) error) error) {
+	writer := openai.NewSSEWriter(w)
+	started := false
+	err := stream(func(chunk any) error {
+		if err := r.Context().Err(); err != nil {
+			return err
+		}
+		started = true
+		return writer.WriteJSON(chunk)
+	})
+	switch {
+	case r.Context().Err() != nil:
+	case err != nil && !started:
+		WriteError(w, err)
+	case err != nil:
+		_ = writer.WriteError(err)
+	default:
+		_ = writer.WriteDone()
+	}
+}
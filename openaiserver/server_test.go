@@ -0,0 +1,232 @@
+package openaiserver_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ibanyu/go-openai"
+	"github.com/ibanyu/go-openai/internal/test/checks"
+	"github.com/ibanyu/go-openai/openaiserver"
+)
+
+var errModelOverloaded = &openai.APIError{
+	Message:        "model overloaded",
+	Type:           "server_error",
+	HTTPStatusCode: http.StatusServiceUnavailable,
+}
+
+// echoService answers with the last message of the request, one word per
+// chunk when streaming, and fails for the "overloaded" model.
+type echoService struct{}
+
+func (echoService) CreateChatCompletion(
+	_ context.Context,
+	request openai.ChatCompletionRequest,
+) (openai.ChatCompletionResponse, error) {
+	if request.Model == "overloaded" {
+		return openai.ChatCompletionResponse{}, errModelOverloaded
+	}
+	response := openai.ChatCompletionResponse{ID: "1", Object: "chat.completion", Model: request.Model,
+		Choices: []openai.ChatCompletionChoice{{
+			Message:      openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: lastContent(request)},
+			FinishReason: openai.FinishReasonStop,
+		}}}
+	if value, ok := request.GetExtension("echo_extension"); ok {
+		response.SetExtension("echoed_extension", value)
+	}
+	return response, nil
+}
+
+func (echoService) CreateChatCompletionStream(
+	_ context.Context,
+	request openai.ChatCompletionRequest,
+	send func(openai.ChatCompletionStreamResponse) error,
+) error {
+	if request.Model == "overloaded" {
+		return errModelOverloaded
+	}
+	for _, word := range strings.Fields(lastContent(request)) {
+		err := send(openai.ChatCompletionStreamResponse{ID: "1", Choices: []openai.ChatCompletionStreamChoice{{
+			Delta: openai.ChatCompletionStreamChoiceDelta{Content: word},
+		}}})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (echoService) CreateCompletion(
+	_ context.Context,
+	request openai.CompletionRequest,
+) (openai.CompletionResponse, error) {
+	prompt, _ := request.Prompt.(string)
+	return openai.CompletionResponse{ID: "1", Choices: []openai.CompletionChoice{{Text: prompt}}}, nil
+}
+
+func (echoService) CreateCompletionStream(
+	_ context.Context,
+	request openai.CompletionRequest,
+	send func(openai.CompletionResponse) error,
+) error {
+	prompt, _ := request.Prompt.(string)
+	if err := send(openai.CompletionResponse{ID: "1", Choices: []openai.CompletionChoice{{Text: prompt}}}); err != nil {
+		return err
+	}
+	return errors.New("generation failed")
+}
+
+func (echoService) CreateEmbeddings(
+	_ context.Context,
+	request openai.EmbeddingRequest,
+) (openai.EmbeddingResponse, error) {
+	return openai.EmbeddingResponse{Object: "list", Model: request.Model, Data: []openai.Embedding{
+		{Object: "embedding", Embedding: []float32{1, float32(request.Dimensions)}},
+	}}, nil
+}
+
+func (echoService) ListModels(_ context.Context) (openai.ModelsList, error) {
+	return openai.ModelsList{Models: []openai.Model{{ID: "echo", Object: "model", OwnedBy: "tests"}}}, nil
+}
+
+func lastContent(request openai.ChatCompletionRequest) string {
+	return request.Messages[len(request.Messages)-1].Content
+}
+
+func setupEchoServer(server *openaiserver.Server) (*openai.Client, func()) {
+	ts := httptest.NewServer(server)
+	config := openai.DefaultConfig("token")
+	config.BaseURL = ts.URL + "/v1"
+	return openai.NewClientWithConfig(config), ts.Close
+}
+
+func echoRequest(model, content string) openai.ChatCompletionRequest {
+	return openai.ChatCompletionRequest{
+		Model:    model,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: content}},
+	}
+}
+
+func TestServerChatCompletion(t *testing.T) {
+	service := echoService{}
+	client, teardown := setupEchoServer(&openaiserver.Server{ChatCompletions: service})
+	defer teardown()
+	ctx := context.Background()
+
+	request := echoRequest("echo", "hello there")
+	request.SetExtension("echo_extension", "x")
+	response, err := client.CreateChatCompletion(ctx, request)
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if response.Choices[0].Message.Content != "hello there" {
+		t.Errorf("unexpected response: %+v", response)
+	}
+	if value, _ := response.GetExtension("echoed_extension"); value != "x" {
+		t.Errorf("expected the extension to be echoed, got %v", value)
+	}
+
+	stream, err := client.CreateChatCompletionStream(ctx, echoRequest("echo", "hello there"))
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+	var words []string
+	for {
+		chunk, recvErr := stream.Recv()
+		if errors.Is(recvErr, io.EOF) {
+			break
+		}
+		checks.NoError(t, recvErr, "Recv error")
+		words = append(words, chunk.Choices[0].Delta.Content)
+	}
+	if strings.Join(words, ",") != "hello,there" {
+		t.Errorf("unexpected chunks: %q", words)
+	}
+
+	for _, stream := range []bool{false, true} {
+		request = echoRequest("overloaded", "hello")
+		request.Stream = stream
+		_, err = client.CreateChatCompletion(ctx, request)
+		if stream {
+			_, err = client.CreateChatCompletionStream(ctx, request)
+		}
+		apiErr := &openai.APIError{}
+		if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != http.StatusServiceUnavailable ||
+			apiErr.Message != "model overloaded" {
+			t.Errorf("expected the service error, got %v", err)
+		}
+	}
+}
+
+func TestServerCompletionStreamError(t *testing.T) {
+	client, teardown := setupEchoServer(&openaiserver.Server{Completions: echoService{}})
+	defer teardown()
+
+	stream, err := client.CreateCompletionStream(context.Background(), openai.CompletionRequest{
+		Model:  openai.GPT3Dot5TurboInstruct,
+		Prompt: "hello",
+	})
+	checks.NoError(t, err, "CreateCompletionStream error")
+	defer stream.Close()
+
+	chunk, err := stream.Recv()
+	checks.NoError(t, err, "Recv error")
+	if chunk.Choices[0].Text != "hello" {
+		t.Errorf("unexpected chunk: %+v", chunk)
+	}
+	_, err = stream.Recv()
+	apiErr := &openai.APIError{}
+	if !errors.As(err, &apiErr) || apiErr.Message != "generation failed" {
+		t.Errorf("expected the error event, got %v", err)
+	}
+}
+
+func TestServerEmbeddingsAndModels(t *testing.T) {
+	client, teardown := setupEchoServer(&openaiserver.Server{Embeddings: echoService{}, Models: echoService{}})
+	defer teardown()
+	ctx := context.Background()
+
+	embeddings, err := client.CreateEmbeddings(ctx, openai.EmbeddingRequest{
+		Input:      "hello",
+		Model:      openai.SmallEmbedding3,
+		Dimensions: 2,
+	})
+	checks.NoError(t, err, "CreateEmbeddings error")
+	if embeddings.Model != openai.SmallEmbedding3 || embeddings.Data[0].Embedding[1] != 2 {
+		t.Errorf("unexpected embeddings: %+v", embeddings)
+	}
+
+	models, err := client.ListModels(ctx)
+	checks.NoError(t, err, "ListModels error")
+	if len(models.Models) != 1 || models.Models[0].ID != "echo" {
+		t.Errorf("unexpected models: %+v", models)
+	}
+
+	_, err = client.CreateChatCompletion(ctx, echoRequest("echo", "hello"))
+	apiErr := &openai.APIError{}
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for an endpoint without service, got %v", err)
+	}
+}
+
+func TestServerInvalidRequests(t *testing.T) {
+	handler := &openaiserver.Server{ChatCompletions: echoService{}, Models: echoService{}}
+	tests := []struct {
+		method, path, body string
+		status             int
+	}{
+		{http.MethodPost, "/v1/chat/completions", "{", http.StatusBadRequest},
+		{http.MethodGet, "/v1/chat/completions", "", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/v1/models", "", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/v1/unknown", "", http.StatusNotFound},
+	}
+	for _, tc := range tests {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body)))
+		if recorder.Code != tc.status || !strings.HasPrefix(recorder.Body.String(), `{"error":{"message":`) {
+			t.Errorf("%s %s: unexpected response %d %s", tc.method, tc.path, recorder.Code, recorder.Body)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	rateLimitWindow = time.Minute
+
+	// estimatedCharsPerToken follows the rule of thumb stated by OpenAI: https://platform.openai.com/tokenizer
+	estimatedCharsPerToken  = 4
+	estimatedMessageTokens  = 4
+	estimatedImagePartToken = 85
+)
+
+// RateLimiterConfig is a configuration of a RateLimiter.
+type RateLimiterConfig struct {
+	// RequestsPerMinute is the initial request budget. Zero means unknown, in which case
+	// the limit is learned from the x-ratelimit-limit-requests response header.
+	RequestsPerMinute int
+	// TokensPerMinute is the initial token budget. Zero means unknown, in which case
+	// the limit is learned from the x-ratelimit-limit-tokens response header.
+	TokensPerMinute int
+	// TokenEstimator estimates the token cost of a request body, such as ChatCompletionRequest
+	// or EmbeddingRequest. A rough character based estimate is used when nil.
+	TokenEstimator func(request any) int
+}
+
+// RateLimiter is a client side limiter of requests and tokens per minute.
+// It is safe for concurrent use, so a single Client can be shared by many goroutines
+// without exceeding the budget the server would enforce with 429 responses.
+type RateLimiter struct {
+	mu sync.Mutex
+
+	requests  rateBucket
+	tokens    rateBucket
+	estimator func(request any) int
+	now       func() time.Time
+}
+
+// rateBucket is a token bucket refilled continuously over rateLimitWindow.
+type rateBucket struct {
+	limit     float64
+	available float64
+	updatedAt time.Time
+	// blockedUntil is set when the server reports the budget as exhausted.
+	blockedUntil time.Time
+}
+
+// NewRateLimiter creates a limiter with the given budgets.
+func NewRateLimiter(config RateLimiterConfig) *RateLimiter {
+	l := &RateLimiter{
+		estimator: config.TokenEstimator,
+		now:       time.Now,
+	}
+	if l.estimator == nil {
+		l.estimator = estimateRequestTokens
+	}
+	now := l.now()
+	l.requests.setLimit(float64(config.RequestsPerMinute), now)
+	l.tokens.setLimit(float64(config.TokensPerMinute), now)
+	return l
+}
+
+// Wait blocks until both the request and the token budgets allow a request costing
+// the given number of tokens, or until the context is done.
+func (l *RateLimiter) Wait(ctx context.Context, tokens int) error {
+	for {
+		l.mu.Lock()
+		now := l.now()
+		l.requests.refill(now)
+		l.tokens.refill(now)
+		wait := l.requests.wait(1, now)
+		if w := l.tokens.wait(float64(tokens), now); w > wait {
+			wait = w
+		}
+		if wait <= 0 {
+			l.requests.take(1)
+			l.tokens.take(float64(tokens))
+			l.mu.Unlock()
+			return nil
+		}
+		l.mu.Unlock()
+
+		if err := sleepContext(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// Update adjusts the budgets to the limits reported by the server.
+func (l *RateLimiter) Update(headers RateLimitHeaders) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.requests.update(headers.LimitRequests, headers.RemainingRequests, headers.ResetRequests, now)
+	l.tokens.update(headers.LimitTokens, headers.RemainingTokens, headers.ResetTokens, now)
+}
+
+// EstimateTokens returns the token cost the limiter charges for the request body.
+func (l *RateLimiter) EstimateTokens(request any) int {
+	return l.estimator(request)
+}
+
+func (b *rateBucket) setLimit(limit float64, now time.Time) {
+	if limit <= 0 || limit == b.limit {
+		return
+	}
+	if b.limit == 0 {
+		b.available = limit
+	} else if b.available > limit {
+		b.available = limit
+	}
+	b.limit = limit
+	b.updatedAt = now
+}
+
+func (b *rateBucket) refill(now time.Time) {
+	if b.limit == 0 {
+		return
+	}
+	elapsed := now.Sub(b.updatedAt)
+	if elapsed <= 0 {
+		return
+	}
+	b.available += b.limit * float64(elapsed) / float64(rateLimitWindow)
+	if b.available > b.limit {
+		b.available = b.limit
+	}
+	b.updatedAt = now
+}
+
+// wait returns how long to wait until cost is available. A cost above the limit
+// only waits for a full bucket, otherwise such requests could never be sent.
+func (b *rateBucket) wait(cost float64, now time.Time) time.Duration {
+	if b.limit == 0 {
+		return 0
+	}
+	if now.Before(b.blockedUntil) {
+		return b.blockedUntil.Sub(now)
+	}
+	if cost > b.limit {
+		cost = b.limit
+	}
+	missing := cost - b.available
+	if missing <= 0 {
+		return 0
+	}
+	return time.Duration(missing / b.limit * float64(rateLimitWindow))
+}
+
+func (b *rateBucket) take(cost float64) {
+	if b.limit == 0 {
+		return
+	}
+	b.available -= cost
+}
+
+func (b *rateBucket) update(limit, remaining int, reset ResetTime, now time.Time) {
+	if limit <= 0 {
+		// headers are not present on this response.
+		return
+	}
+	b.refill(now)
+	b.setLimit(float64(limit), now)
+	if float64(remaining) < b.available {
+		b.available = float64(remaining)
+	}
+	if remaining == 0 {
+		b.blockedUntil = now.Add(reset.Duration())
+	}
+}
+
+type tokenCostContextKey struct{}
+
+func withTokenCost(ctx context.Context, tokens int) context.Context {
+	return context.WithValue(ctx, tokenCostContextKey{}, tokens)
+}
+
+func tokenCostFromContext(ctx context.Context) int {
+	tokens, _ := ctx.Value(tokenCostContextKey{}).(int)
+	return tokens
+}
+
+// limitRequest waits for the configured RateLimiter before the request is sent.
+func (c *Client) limitRequest(req *http.Request) error {
+	if c.config.RateLimiter == nil {
+		return nil
+	}
+	return c.config.RateLimiter.Wait(req.Context(), tokenCostFromContext(req.Context()))
+}
+
+// observeRateLimits feeds the rate limit headers of a response to the configured RateLimiter.
+func (c *Client) observeRateLimits(resp *http.Response) {
+	if c.config.RateLimiter == nil || resp == nil {
+		return
+	}
+	c.config.RateLimiter.Update(newRateLimitHeaders(resp.Header))
+}
+
+// estimateRequestTokens approximates the number of tokens a request counts against
+// the tokens per minute limit: the prompt plus the maximum number of generated tokens.
+func estimateRequestTokens(request any) int {
+	switch r := request.(type) {
+	case ChatCompletionRequest:
+		return estimateChatCompletionTokens(r)
+	case *ChatCompletionRequest:
+		return estimateChatCompletionTokens(*r)
+	case CompletionRequest:
+		return estimateInputTokens(r.Prompt) + maxInt(r.MaxTokens, 1)*maxInt(r.N, 1)
+	case EmbeddingRequest:
+		return estimateInputTokens(r.Input)
+	default:
+		return 0
+	}
+}
+
+func estimateChatCompletionTokens(r ChatCompletionRequest) int {
+	tokens := 0
+	for _, message := range r.Messages {
+		tokens += estimatedMessageTokens
+		tokens += estimateTextTokens(message.Role) + estimateTextTokens(message.Name)
+		tokens += estimateTextTokens(message.Content)
+		for _, part := range message.MultiContent {
+			if part.Type == ChatMessagePartTypeImageURL {
+				tokens += estimatedImagePartToken
+				continue
+			}
+			tokens += estimateTextTokens(part.Text)
+		}
+		for _, call := range message.ToolCalls {
+			tokens += estimateTextTokens(call.Function.Name) + estimateTextTokens(call.Function.Arguments)
+		}
+	}
+	if len(r.Tools) > 0 {
+		if b, err := json.Marshal(r.Tools); err == nil {
+			tokens += estimateTextTokens(string(b))
+		}
+	}
+
+	maxTokens := r.MaxCompletionTokens
+	if maxTokens == 0 {
+		maxTokens = r.MaxTokens
+	}
+	return tokens + maxTokens*maxInt(r.N, 1)
+}
+
+func estimateInputTokens(input any) int {
+	switch v := input.(type) {
+	case string:
+		return estimateTextTokens(v)
+	case []string:
+		tokens := 0
+		for _, s := range v {
+			tokens += estimateTextTokens(s)
+		}
+		return tokens
+	case []int:
+		return len(v)
+	case [][]int:
+		tokens := 0
+		for _, t := range v {
+			tokens += len(t)
+		}
+		return tokens
+	case []any:
+		tokens := 0
+		for _, item := range v {
+			tokens += estimateInputTokens(item)
+		}
+		return tokens
+	default:
+		return 0
+	}
+}
+
+func estimateTextTokens(s string) int {
+	return (len(s) + estimatedCharsPerToken - 1) / estimatedCharsPerToken
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,157 @@
+package openai //nolint:testpackage // testing private field
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ibanyu/go-openai/internal/test"
+	"github.com/ibanyu/go-openai/internal/test/checks"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func TestRateLimiterRefillsOverTime(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	limiter := NewRateLimiter(RateLimiterConfig{RequestsPerMinute: 60, TokensPerMinute: 600})
+	limiter.now = clock.Now
+	limiter.requests.updatedAt = clock.now
+	limiter.tokens.updatedAt = clock.now
+
+	limiter.requests.take(60)
+	if wait := limiter.requests.wait(1, clock.now); wait != time.Second {
+		t.Fatalf("expected to wait 1s for a request, got %v", wait)
+	}
+
+	clock.now = clock.now.Add(time.Second)
+	limiter.requests.refill(clock.now)
+	if wait := limiter.requests.wait(1, clock.now); wait != 0 {
+		t.Fatalf("expected no wait after refill, got %v", wait)
+	}
+
+	if wait := limiter.tokens.wait(1000, clock.now); wait != 0 {
+		t.Fatalf("expected cost above the limit to wait for a full bucket only, got %v", wait)
+	}
+}
+
+func TestRateLimiterWaitRespectsContext(t *testing.T) {
+	limiter := NewRateLimiter(RateLimiterConfig{RequestsPerMinute: 1})
+	checks.NoError(t, limiter.Wait(context.Background(), 0), "first Wait should not block")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := limiter.Wait(ctx, 0)
+	checks.ErrorIs(t, err, context.DeadlineExceeded, "second Wait should block until the deadline")
+}
+
+func TestRateLimiterUpdateFromHeaders(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	limiter := NewRateLimiter(RateLimiterConfig{})
+	limiter.now = clock.Now
+
+	limiter.Update(RateLimitHeaders{})
+	if limiter.requests.limit != 0 || limiter.tokens.limit != 0 {
+		t.Fatalf("missing headers should not set limits")
+	}
+
+	limiter.Update(RateLimitHeaders{
+		LimitRequests:     100,
+		LimitTokens:       1000,
+		RemainingRequests: 0,
+		RemainingTokens:   500,
+		ResetRequests:     "2s",
+		ResetTokens:       "30s",
+	})
+	if limiter.requests.limit != 100 || limiter.tokens.limit != 1000 {
+		t.Fatalf("limits were not learned: %+v %+v", limiter.requests, limiter.tokens)
+	}
+	if limiter.tokens.available != 500 {
+		t.Fatalf("expected 500 available tokens, got %v", limiter.tokens.available)
+	}
+	if wait := limiter.requests.wait(1, clock.now); wait != 2*time.Second {
+		t.Fatalf("expected to wait for the reset, got %v", wait)
+	}
+}
+
+func TestEstimateRequestTokens(t *testing.T) {
+	chat := ChatCompletionRequest{
+		MaxTokens: 10,
+		Messages: []ChatCompletionMessage{
+			{Role: ChatMessageRoleUser, Content: "12345678"},
+		},
+	}
+	// 4 message overhead + 1 role + 2 content + 10 completion
+	if tokens := estimateRequestTokens(chat); tokens != 17 {
+		t.Fatalf("unexpected chat estimate: %d", tokens)
+	}
+	if tokens := estimateRequestTokens(&chat); tokens != 17 {
+		t.Fatalf("unexpected chat pointer estimate: %d", tokens)
+	}
+
+	embedding := EmbeddingRequest{Input: []string{"1234", "12345678"}}
+	if tokens := estimateRequestTokens(embedding); tokens != 3 {
+		t.Fatalf("unexpected embedding estimate: %d", tokens)
+	}
+	if tokens := estimateRequestTokens(EmbeddingRequest{Input: [][]int{{1, 2}, {3}}}); tokens != 3 {
+		t.Fatalf("unexpected token embedding estimate: %d", tokens)
+	}
+	if tokens := estimateRequestTokens(struct{}{}); tokens != 0 {
+		t.Fatalf("unexpected estimate for unknown request: %d", tokens)
+	}
+}
+
+func TestClientRateLimiterBlocksBeforeServer(t *testing.T) {
+	server := test.NewTestServer()
+	ts := server.OpenAITestServer()
+	ts.Start()
+	defer ts.Close()
+
+	requests := 0
+	server.RegisterHandler("/v1/models", func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		w.Header().Set("x-ratelimit-limit-requests", "10")
+		w.Header().Set("x-ratelimit-remaining-requests", "0")
+		w.Header().Set("x-ratelimit-reset-requests", "1m0s")
+		fmt.Fprint(w, `{"data":[]}`)
+	})
+
+	config := DefaultConfig(test.GetTestToken())
+	config.BaseURL = ts.URL + "/v1"
+	config.RateLimiter = NewRateLimiter(RateLimiterConfig{})
+	client := NewClientWithConfig(config)
+
+	_, err := client.ListModels(context.Background())
+	checks.NoError(t, err, "ListModels error")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = client.ListModels(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the limiter to block until the deadline, got %v", err)
+	}
+	if requests != 1 {
+		t.Fatalf("expected a single request to reach the server, got %d", requests)
+	}
+}
+
+func TestClientRateLimiterTokenCost(t *testing.T) {
+	limiter := NewRateLimiter(RateLimiterConfig{TokenEstimator: func(any) int { return 42 }})
+	config := DefaultConfig("token")
+	config.RateLimiter = limiter
+	client := NewClientWithConfig(config)
+
+	req, err := client.newRequest(context.Background(), http.MethodPost, "/foo", withBody(ChatCompletionRequest{}))
+	checks.NoError(t, err, "newRequest error")
+	if cost := tokenCostFromContext(req.Context()); cost != 42 {
+		t.Fatalf("expected token cost 42, got %d", cost)
+	}
+}
@@ -2,7 +2,7 @@ package openai
 
 import (
 	"errors"
-	"strings"
+	"fmt"
 )
 
 var (
@@ -28,53 +28,82 @@ var (
 	ErrReasoningModelLimitationsOther    = errors.New("this model has beta-limitations, temperature, top_p and n are fixed at 1, while presence_penalty and frequency_penalty are fixed at 0") //nolint:lll
 )
 
-// ReasoningValidator handles validation for o-series model requests.
-type ReasoningValidator struct{}
+var ErrModelUnsupportedParameter = errors.New("this model does not support the parameter")
 
-// NewReasoningValidator creates a new validator for o-series models.
+// ReasoningValidator validates chat completion requests against the
+// capabilities of their model in a ModelRegistry.
+type ReasoningValidator struct {
+	registry *ModelRegistry
+}
+
+// NewReasoningValidator creates a new validator using DefaultModelRegistry.
 func NewReasoningValidator() *ReasoningValidator {
-	return &ReasoningValidator{}
+	return NewReasoningValidatorWithRegistry(DefaultModelRegistry)
 }
 
-// Validate performs all validation checks for o-series models.
-func (v *ReasoningValidator) Validate(request ChatCompletionRequest) error {
-	o1Series := strings.HasPrefix(request.Model, "o1")
-	o3Series := strings.HasPrefix(request.Model, "o3")
+// NewReasoningValidatorWithRegistry creates a new validator using registry.
+func NewReasoningValidatorWithRegistry(registry *ModelRegistry) *ReasoningValidator {
+	return &ReasoningValidator{registry: registry}
+}
 
-	if !o1Series && !o3Series {
+// Validate checks that the model of request supports its parameters.
+// Requests for models unknown to the registry are not checked.
+func (v *ReasoningValidator) Validate(request ChatCompletionRequest) error {
+	capabilities, ok := v.registry.Lookup(request.Model)
+	if !ok {
 		return nil
 	}
 
-	if err := v.validateReasoningModelParams(request); err != nil {
-		return err
+	for _, param := range capabilities.unsupportedParams() {
+		if modelParamSet(request, param) {
+			return unsupportedParamError(capabilities, param)
+		}
 	}
 
 	return nil
 }
 
-// validateReasoningModelParams checks reasoning model parameters.
-func (v *ReasoningValidator) validateReasoningModelParams(request ChatCompletionRequest) error {
-	if request.MaxTokens > 0 {
-		return ErrReasoningModelMaxTokensDeprecated
-	}
-	if request.LogProbs {
-		return ErrReasoningModelLimitationsLogprobs
-	}
-	if request.Temperature > 0 && request.Temperature != 1 {
-		return ErrReasoningModelLimitationsOther
-	}
-	if request.TopP > 0 && request.TopP != 1 {
-		return ErrReasoningModelLimitationsOther
-	}
-	if request.N > 0 && request.N != 1 {
-		return ErrReasoningModelLimitationsOther
-	}
-	if request.PresencePenalty > 0 {
-		return ErrReasoningModelLimitationsOther
-	}
-	if request.FrequencyPenalty > 0 {
-		return ErrReasoningModelLimitationsOther
+// modelParamSet reports whether param of request is set to anything but its
+// default. Sampling parameters default to 1.
+func modelParamSet(request ChatCompletionRequest, param ModelParam) bool {
+	switch param {
+	case ModelParamMaxTokens:
+		return request.MaxTokens > 0
+	case ModelParamLogProbs:
+		return request.LogProbs
+	case ModelParamTopLogProbs:
+		return request.TopLogProbs > 0
+	case ModelParamTemperature:
+		return request.Temperature > 0 && request.Temperature != 1
+	case ModelParamTopP:
+		return request.TopP > 0 && request.TopP != 1
+	case ModelParamN:
+		return request.N > 0 && request.N != 1
+	case ModelParamPresencePenalty:
+		return request.PresencePenalty != 0
+	case ModelParamFrequencyPenalty:
+		return request.FrequencyPenalty != 0
+	case ModelParamLogitBias:
+		return len(request.LogitBias) > 0
+	default:
+		return false
 	}
+}
 
-	return nil
+// unsupportedParamError keeps returning the errors reasoning models have
+// always been validated with.
+func unsupportedParamError(capabilities ModelCapabilities, param ModelParam) error {
+	if capabilities.Reasoning {
+		switch param {
+		case ModelParamMaxTokens:
+			return ErrReasoningModelMaxTokensDeprecated
+		case ModelParamLogProbs, ModelParamTopLogProbs:
+			return ErrReasoningModelLimitationsLogprobs
+		case ModelParamTemperature, ModelParamTopP, ModelParamN,
+			ModelParamPresencePenalty, ModelParamFrequencyPenalty:
+			return ErrReasoningModelLimitationsOther
+		case ModelParamLogitBias:
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrModelUnsupportedParameter, param)
 }
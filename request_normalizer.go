@@ -0,0 +1,145 @@
+package openai
+
+import "fmt"
+
+// RequestRewrite describes a change made to a request by a RequestNormalizer.
+type RequestRewrite struct {
+	// Param is the JSON name of the rewritten parameter.
+	Param string
+	// Description describes the change.
+	Description string
+}
+
+func (r RequestRewrite) String() string {
+	return r.Param + ": " + r.Description
+}
+
+// RequestNormalizer adapts chat completion requests to the capabilities of
+// their model in a ModelRegistry, instead of letting them fail validation.
+type RequestNormalizer struct {
+	registry *ModelRegistry
+}
+
+// NewRequestNormalizer creates a new normalizer using registry.
+func NewRequestNormalizer(registry *ModelRegistry) *RequestNormalizer {
+	return &RequestNormalizer{registry: registry}
+}
+
+// Normalize returns request rewritten for its model, and the rewrites made:
+//   - Functions and FunctionCall are replaced with Tools and ToolChoice,
+//   - MaxTokens is moved to MaxCompletionTokens for reasoning models,
+//   - parameters the model does not support are dropped,
+//   - system messages become developer messages for models with DeveloperRole.
+//
+// Only functions are rewritten for models unknown to the registry. The
+// slices of request are copied before being changed.
+func (n *RequestNormalizer) Normalize(request ChatCompletionRequest) (ChatCompletionRequest, []RequestRewrite) {
+	var rewrites []RequestRewrite
+	rewrite := func(param string, format string, args ...any) {
+		rewrites = append(rewrites, RequestRewrite{Param: param, Description: fmt.Sprintf(format, args...)})
+	}
+
+	if len(request.Functions) > 0 {
+		request.Tools = append([]Tool(nil), request.Tools...)
+		for _, function := range request.Functions {
+			function := function
+			request.Tools = append(request.Tools, Tool{Type: ToolTypeFunction, Function: &function})
+		}
+		rewrite("functions", "replaced %d functions with tools", len(request.Functions))
+		request.Functions = nil
+	}
+	if request.FunctionCall != nil {
+		request.ToolChoice = functionCallToolChoice(request.FunctionCall)
+		rewrite("function_call", "replaced with tool_choice")
+		request.FunctionCall = nil
+	}
+
+	capabilities, ok := n.registry.Lookup(request.Model)
+	if !ok {
+		return request, rewrites
+	}
+
+	if capabilities.Reasoning && request.MaxTokens > 0 {
+		if request.MaxCompletionTokens == 0 {
+			request.MaxCompletionTokens = request.MaxTokens
+			rewrite(string(ModelParamMaxTokens), "moved %d to max_completion_tokens", request.MaxTokens)
+		} else {
+			rewrite(string(ModelParamMaxTokens), "dropped %d in favor of max_completion_tokens", request.MaxTokens)
+		}
+		request.MaxTokens = 0
+	}
+	for _, param := range capabilities.unsupportedParams() {
+		if modelParamSet(request, param) {
+			clearModelParam(&request, param)
+			rewrite(string(param), "dropped, not supported by %s", request.Model)
+		}
+	}
+
+	if capabilities.DeveloperRole {
+		converted := 0
+		request.Messages = append([]ChatCompletionMessage(nil), request.Messages...)
+		for i := range request.Messages {
+			if request.Messages[i].Role == ChatMessageRoleSystem {
+				request.Messages[i].Role = ChatMessageRoleDeveloper
+				converted++
+			}
+		}
+		if converted > 0 {
+			rewrite("messages", "converted %d system messages to developer messages", converted)
+		}
+	}
+	return request, rewrites
+}
+
+// functionCallToolChoice converts the deprecated function_call parameter,
+// "none", "auto" or a function name, to the equivalent tool_choice.
+func functionCallToolChoice(functionCall any) any {
+	var name string
+	switch call := functionCall.(type) {
+	case FunctionCall:
+		name = call.Name
+	case *FunctionCall:
+		name = call.Name
+	case map[string]any:
+		name, _ = call["name"].(string)
+	case map[string]string:
+		name = call["name"]
+	default:
+		return functionCall
+	}
+	return ToolChoice{Type: ToolTypeFunction, Function: ToolFunction{Name: name}}
+}
+
+func clearModelParam(request *ChatCompletionRequest, param ModelParam) {
+	switch param {
+	case ModelParamMaxTokens:
+		request.MaxTokens = 0
+	case ModelParamLogProbs:
+		request.LogProbs = false
+	case ModelParamTopLogProbs:
+		request.TopLogProbs = 0
+	case ModelParamTemperature:
+		request.Temperature = 0
+	case ModelParamTopP:
+		request.TopP = 0
+	case ModelParamN:
+		request.N = 0
+	case ModelParamPresencePenalty:
+		request.PresencePenalty = 0
+	case ModelParamFrequencyPenalty:
+		request.FrequencyPenalty = 0
+	case ModelParamLogitBias:
+		request.LogitBias = nil
+	}
+}
+
+func (c *Client) normalizeChatCompletionRequest(request ChatCompletionRequest) ChatCompletionRequest {
+	if !c.config.NormalizeRequests {
+		return request
+	}
+	request, rewrites := NewRequestNormalizer(c.modelRegistry()).Normalize(request)
+	if len(rewrites) > 0 && c.config.OnRequestNormalized != nil {
+		c.config.OnRequestNormalized(request.Model, rewrites)
+	}
+	return request
+}
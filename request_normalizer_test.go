@@ -0,0 +1,99 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ibanyu/go-openai"
+	"github.com/ibanyu/go-openai/internal/test"
+	"github.com/ibanyu/go-openai/internal/test/checks"
+)
+
+func TestRequestNormalizer(t *testing.T) {
+	normalizer := openai.NewRequestNormalizer(openai.NewModelRegistry())
+	request := openai.ChatCompletionRequest{
+		Model:       openai.O3Mini,
+		MaxTokens:   100,
+		Temperature: 0.2,
+		TopP:        0.9,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: "Be brief."},
+			{Role: openai.ChatMessageRoleUser, Content: "Hello!"},
+		},
+		Functions:    []openai.FunctionDefinition{{Name: "get_weather"}},
+		FunctionCall: openai.FunctionCall{Name: "get_weather"},
+	}
+
+	normalized, rewrites := normalizer.Normalize(request)
+	if normalized.MaxTokens != 0 || normalized.MaxCompletionTokens != 100 ||
+		normalized.Temperature != 0 || normalized.TopP != 0 {
+		t.Errorf("unexpected parameters: %+v", normalized)
+	}
+	if normalized.Messages[0].Role != openai.ChatMessageRoleDeveloper || request.Messages[0].Role != "system" {
+		t.Errorf("expected a developer message in a copy of the messages, got %+v", normalized.Messages)
+	}
+	if len(normalized.Functions) != 0 || len(normalized.Tools) != 1 ||
+		normalized.Tools[0].Function.Name != "get_weather" || normalized.FunctionCall != nil {
+		t.Errorf("expected functions to be replaced with tools, got %+v", normalized)
+	}
+	if choice, ok := normalized.ToolChoice.(openai.ToolChoice); !ok || choice.Function.Name != "get_weather" {
+		t.Errorf("unexpected tool choice: %+v", normalized.ToolChoice)
+	}
+
+	var params []string
+	for _, rewrite := range rewrites {
+		params = append(params, rewrite.Param)
+	}
+	want := []string{"functions", "function_call", "max_tokens", "temperature", "top_p", "messages"}
+	if fmt.Sprint(params) != fmt.Sprint(want) {
+		t.Errorf("unexpected rewrites: %v", rewrites)
+	}
+
+	unchanged, rewrites := normalizer.Normalize(openai.ChatCompletionRequest{Model: openai.GPT4o, MaxTokens: 10})
+	if len(rewrites) != 0 || unchanged.MaxTokens != 10 {
+		t.Errorf("expected no rewrites for %s, got %v", openai.GPT4o, rewrites)
+	}
+}
+
+func TestClientNormalizeRequests(t *testing.T) {
+	server := test.NewTestServer()
+	ts := server.OpenAITestServer()
+	ts.Start()
+	defer ts.Close()
+
+	var rewrites []openai.RequestRewrite
+	config := openai.DefaultConfig(test.GetTestToken())
+	config.BaseURL = ts.URL + "/v1"
+	config.NormalizeRequests = true
+	config.OnRequestNormalized = func(model string, r []openai.RequestRewrite) {
+		if model != openai.O1Mini {
+			t.Errorf("unexpected model: %s", model)
+		}
+		rewrites = r
+	}
+	client := openai.NewClientWithConfig(config)
+
+	var sent map[string]any
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&sent), "decode request error")
+		fmt.Fprint(w, `{"id":"1","choices":[]}`)
+	})
+
+	_, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model:     openai.O1Mini,
+		MaxTokens: 5,
+		LogProbs:  true,
+		Messages:  []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello!"}},
+	})
+	checks.NoError(t, err, "CreateChatCompletion error")
+
+	if _, ok := sent["max_tokens"]; ok || sent["max_completion_tokens"] != float64(5) || sent["logprobs"] != nil {
+		t.Errorf("unexpected request: %v", sent)
+	}
+	if len(rewrites) != 2 || rewrites[1].String() != "logprobs: dropped, not supported by o1-mini" {
+		t.Errorf("unexpected rewrites: %v", rewrites)
+	}
+}
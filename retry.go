@@ -0,0 +1,253 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultRetryMaxAttempts    = 3
+	defaultRetryInitialBackoff = 500 * time.Millisecond
+	defaultRetryMaxBackoff     = 30 * time.Second
+	defaultRetryMultiplier     = 2
+	defaultRetryJitter         = 0.2
+)
+
+// RetryPolicy describes how failed requests are retried.
+// A nil policy on ClientConfig disables retries.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// Values lower than 2 disable retries.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the computed exponential delay.
+	MaxBackoff time.Duration
+	// Multiplier is applied to the delay after every attempt.
+	Multiplier float64
+	// Jitter is the fraction of the delay, between 0 and 1, that is randomized.
+	Jitter float64
+	// MaxRetryAfter caps the delay requested by the server through Retry-After
+	// or x-ratelimit-reset-* headers. If the server asks to wait longer the
+	// error is returned immediately. Zero means no limit.
+	MaxRetryAfter time.Duration
+	// RetryableStatusCodes lists the HTTP status codes which are retried.
+	RetryableStatusCodes []int
+	// RetryableErrorTypes lists APIError.Type values which are retried
+	// regardless of the HTTP status code.
+	RetryableErrorTypes []string
+}
+
+// DefaultRetryPolicy returns a policy retrying rate limit and server errors
+// up to three times with exponential backoff.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    defaultRetryMaxAttempts,
+		InitialBackoff: defaultRetryInitialBackoff,
+		MaxBackoff:     defaultRetryMaxBackoff,
+		Multiplier:     defaultRetryMultiplier,
+		Jitter:         defaultRetryJitter,
+		RetryableStatusCodes: []int{
+			http.StatusRequestTimeout,
+			http.StatusConflict,
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		RetryableErrorTypes: []string{"server_error"},
+	}
+}
+
+// doRequest sends the request, retrying it according to the configured RetryPolicy.
+// Failure responses are returned with a readable body so that callers can decode the error.
+func (c *Client) doRequest(req *http.Request) (*http.Response, error) {
+	policy := c.config.RetryPolicy
+	if policy == nil || policy.MaxAttempts < 2 {
+		return c.do(req)
+	}
+
+	if err := makeBodyReplayable(req); err != nil {
+		return nil, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.do(req)
+		if attempt >= policy.MaxAttempts {
+			return resp, err
+		}
+
+		var retryAfter time.Duration
+		switch {
+		case err != nil:
+			if req.Context().Err() != nil {
+				return resp, err
+			}
+		case isFailureStatusCode(resp):
+			body, readErr := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if readErr != nil {
+				return nil, readErr
+			}
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+			if !policy.isRetryableResponse(resp.StatusCode, body) {
+				return resp, nil
+			}
+			retryAfter = retryAfterFromHeader(resp)
+			if policy.MaxRetryAfter > 0 && retryAfter > policy.MaxRetryAfter {
+				return resp, nil
+			}
+		default:
+			return resp, nil
+		}
+
+		if sleepErr := sleepContext(req.Context(), policy.delay(attempt, retryAfter)); sleepErr != nil {
+			return nil, sleepErr
+		}
+		if err = rewindBody(req); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *RetryPolicy) isRetryableResponse(statusCode int, body []byte) bool {
+	if containsInt(p.RetryableStatusCodes, statusCode) {
+		return true
+	}
+	if len(p.RetryableErrorTypes) == 0 {
+		return false
+	}
+	var errRes ErrorResponse
+	if err := json.Unmarshal(body, &errRes); err != nil || errRes.Error == nil {
+		return false
+	}
+	return containsString(p.RetryableErrorTypes, errRes.Error.Type)
+}
+
+// delay returns how long to wait before the next attempt. The server provided
+// retryAfter is used as a lower bound of the exponential backoff.
+func (p *RetryPolicy) delay(attempt int, retryAfter time.Duration) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	backoff := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		jitter := math.Min(p.Jitter, 1)
+		backoff -= backoff * jitter * rand.Float64() //nolint:gosec // jitter does not need a secure source
+	}
+
+	d := time.Duration(backoff)
+	if retryAfter > d {
+		d = retryAfter
+	}
+	return d
+}
+
+// retryAfterFromHeader returns the delay requested by the server. It honors
+// retry-after-ms, Retry-After (seconds or HTTP date) and, for rate limit
+// responses, the reset time of the exhausted x-ratelimit-* budget.
+func retryAfterFromHeader(resp *http.Response) time.Duration {
+	if ms, err := strconv.ParseFloat(resp.Header.Get("retry-after-ms"), 64); err == nil && ms > 0 {
+		return time.Duration(ms * float64(time.Millisecond))
+	}
+	if v := resp.Header.Get("Retry-After"); v != "" {
+		if seconds, err := strconv.ParseFloat(v, 64); err == nil && seconds > 0 {
+			return time.Duration(seconds * float64(time.Second))
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			if d := time.Until(t); d > 0 {
+				return d
+			}
+		}
+	}
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return 0
+	}
+
+	rateLimit := newRateLimitHeaders(resp.Header)
+	var d time.Duration
+	if rateLimit.RemainingRequests == 0 {
+		d = rateLimit.ResetRequests.Duration()
+	}
+	if rateLimit.RemainingTokens == 0 {
+		if tokens := rateLimit.ResetTokens.Duration(); tokens > d {
+			d = tokens
+		}
+	}
+	return d
+}
+
+// makeBodyReplayable buffers request bodies which can not be recreated, so that
+// they can be sent again. Bodies built by the request and form builders are
+// backed by bytes.Buffer and already provide GetBody.
+func makeBodyReplayable(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return err
+	}
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	req.Body, _ = req.GetBody()
+	return nil
+}
+
+func rewindBody(req *http.Request) error {
+	if req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return err
+	}
+	req.Body = body
+	return nil
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func containsInt(s []int, v int) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,228 @@
+package openai_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ibanyu/go-openai"
+	"github.com/ibanyu/go-openai/internal/test/checks"
+)
+
+func fastRetryPolicy() *openai.RetryPolicy {
+	policy := openai.DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	policy.MaxBackoff = 5 * time.Millisecond
+	policy.Jitter = 0
+	return policy
+}
+
+func TestRetryOnRateLimit(t *testing.T) {
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.RetryPolicy = fastRetryPolicy()
+	})
+	defer teardown()
+
+	attempts := 0
+	server.RegisterHandler("/v1/models", func(w http.ResponseWriter, _ *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.Header().Set("retry-after-ms", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"error":{"message":"rate limited","type":"requests"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"data":[{"id":"gpt-4o"}]}`)
+	})
+
+	models, err := client.ListModels(context.Background())
+	checks.NoError(t, err, "ListModels error")
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts)
+	}
+	if len(models.Models) != 1 || models.Models[0].ID != "gpt-4o" {
+		t.Fatalf("unexpected models: %+v", models.Models)
+	}
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.RetryPolicy = fastRetryPolicy()
+	})
+	defer teardown()
+
+	attempts := 0
+	server.RegisterHandler("/v1/models", func(w http.ResponseWriter, _ *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprint(w, `{"error":{"message":"overloaded","type":"server_error"}}`)
+	})
+
+	_, err := client.ListModels(context.Background())
+	var apiErr *openai.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected APIError, got %v", err)
+	}
+	if apiErr.HTTPStatusCode != http.StatusServiceUnavailable || apiErr.Message != "overloaded" {
+		t.Fatalf("unexpected error: %v", apiErr)
+	}
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestRetrySkipsNonRetryableErrors(t *testing.T) {
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.RetryPolicy = fastRetryPolicy()
+	})
+	defer teardown()
+
+	attempts := 0
+	server.RegisterHandler("/v1/models", func(w http.ResponseWriter, _ *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error":{"message":"bad request","type":"invalid_request_error"}}`)
+	})
+
+	_, err := client.ListModels(context.Background())
+	checks.HasError(t, err, "ListModels should fail")
+	if attempts != 1 {
+		t.Fatalf("expected 1 attempt, got %d", attempts)
+	}
+}
+
+func TestRetryOnErrorType(t *testing.T) {
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.RetryPolicy = fastRetryPolicy()
+	})
+	defer teardown()
+
+	attempts := 0
+	server.RegisterHandler("/v1/models", func(w http.ResponseWriter, _ *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"message":"try again","type":"server_error"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"data":[]}`)
+	})
+
+	_, err := client.ListModels(context.Background())
+	checks.NoError(t, err, "ListModels error")
+	if attempts != 2 {
+		t.Fatalf("expected 2 attempts, got %d", attempts)
+	}
+}
+
+func TestRetryRewindsMultipartBody(t *testing.T) {
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.RetryPolicy = fastRetryPolicy()
+	})
+	defer teardown()
+
+	var bodies []string
+	server.RegisterHandler("/v1/files", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		checks.NoError(t, err, "read body error")
+		bodies = append(bodies, string(body))
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, `{"id":"file-1","object":"file"}`)
+	})
+
+	file, err := client.CreateFileBytes(context.Background(), openai.FileBytesRequest{
+		Name:    "foo.jsonl",
+		Bytes:   []byte("hello"),
+		Purpose: openai.PurposeFineTune,
+	})
+	checks.NoError(t, err, "CreateFileBytes error")
+	if file.ID != "file-1" {
+		t.Fatalf("unexpected file: %+v", file)
+	}
+	if len(bodies) != 2 || bodies[0] == "" || bodies[0] != bodies[1] {
+		t.Fatalf("request body was not replayed: %q", bodies)
+	}
+}
+
+func TestRetryHonorsMaxRetryAfter(t *testing.T) {
+	policy := fastRetryPolicy()
+	policy.MaxRetryAfter = time.Second
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.RetryPolicy = policy
+	})
+	defer teardown()
+
+	attempts := 0
+	server.RegisterHandler("/v1/models", func(w http.ResponseWriter, _ *http.Request) {
+		attempts++
+		w.Header().Set("x-ratelimit-remaining-tokens", "0")
+		w.Header().Set("x-ratelimit-remaining-requests", "10")
+		w.Header().Set("x-ratelimit-reset-tokens", "1m0s")
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"error":{"message":"rate limited","type":"tokens"}}`)
+	})
+
+	_, err := client.ListModels(context.Background())
+	checks.HasError(t, err, "ListModels should fail")
+	if attempts != 1 {
+		t.Fatalf("expected 1 attempt, got %d", attempts)
+	}
+}
+
+func TestRetryStopsOnContextCancel(t *testing.T) {
+	policy := fastRetryPolicy()
+	policy.InitialBackoff = time.Minute
+	policy.MaxBackoff = time.Minute
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.RetryPolicy = policy
+	})
+	defer teardown()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	server.RegisterHandler("/v1/models", func(w http.ResponseWriter, _ *http.Request) {
+		cancel()
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	_, err := client.ListModels(ctx)
+	checks.ErrorIs(t, err, context.Canceled, "ListModels should return context.Canceled")
+}
+
+func TestRetryStream(t *testing.T) {
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.RetryPolicy = fastRetryPolicy()
+	})
+	defer teardown()
+
+	attempts := 0
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"id\":\"1\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"hi\"}}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{
+		Model:    openai.GPT4oMini,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello!"}},
+	})
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+
+	resp, err := stream.Recv()
+	checks.NoError(t, err, "stream.Recv error")
+	if resp.Choices[0].Delta.Content != "hi" || attempts != 2 {
+		t.Fatalf("unexpected stream response %+v after %d attempts", resp, attempts)
+	}
+}
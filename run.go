@@ -113,6 +113,8 @@ type RunRequest struct {
 	ResponseFormat any `json:"response_format,omitempty"`
 	// Disable the default behavior of parallel tool calls by setting it: false.
 	ParallelToolCalls any `json:"parallel_tool_calls,omitempty"`
+	// Stream is set by CreateRunStream and CreateThreadAndRunStream.
+	Stream bool `json:"stream,omitempty"`
 }
 
 // ThreadTruncationStrategy defines the truncation strategy to use for the thread.
@@ -154,6 +156,8 @@ type RunList struct {
 
 type SubmitToolOutputsRequest struct {
 	ToolOutputs []ToolOutput `json:"tool_outputs"`
+	// Stream is set by SubmitToolOutputsStream.
+	Stream bool `json:"stream,omitempty"`
 }
 
 type ToolOutput struct {
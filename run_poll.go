@@ -0,0 +1,151 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultRunPollInitialInterval = 500 * time.Millisecond
+	defaultRunPollMaxInterval     = 5 * time.Second
+	defaultRunPollMultiplier      = 1.5
+	runPollMessagesPageSize       = 100
+)
+
+var ErrRunPollUnknownStatus = errors.New("run has an unknown status")
+
+// RunPollOptions configures PollRun.
+type RunPollOptions struct {
+	// InitialInterval is the wait before the first poll. Defaults to 500ms.
+	InitialInterval time.Duration
+	// MaxInterval caps the wait between polls. Defaults to 5s.
+	MaxInterval time.Duration
+	// Multiplier grows the wait after every poll. Defaults to 1.5.
+	Multiplier float64
+	// ToolHandlers executes the tool calls of runs that require action.
+	// When nil, PollRun returns as soon as the run requires action.
+	ToolHandlers ToolHandlers
+}
+
+// RunPollResult is the outcome of PollRun.
+type RunPollResult struct {
+	// Run is the run in its final state.
+	Run Run
+	// Messages are the messages created by the run, oldest first.
+	Messages []Message
+}
+
+// PollRun polls a run until it reaches a terminal state, submitting the
+// outputs of ToolHandlers whenever the run requires action. It returns the
+// final run and the messages it created.
+func (c *Client) PollRun(
+	ctx context.Context,
+	threadID string,
+	runID string,
+	options RunPollOptions,
+) (result RunPollResult, err error) {
+	options.setDefaults()
+	interval := options.InitialInterval
+	for {
+		if err = sleepContext(ctx, interval); err != nil {
+			return
+		}
+
+		result.Run, err = c.RetrieveRun(ctx, threadID, runID)
+		if err != nil {
+			return
+		}
+
+		switch result.Run.Status {
+		case RunStatusCompleted, RunStatusFailed, RunStatusCancelled, RunStatusExpired, RunStatusIncomplete:
+			result.Messages, err = c.listRunMessages(ctx, threadID, runID)
+			return
+		case RunStatusRequiresAction:
+			if options.ToolHandlers == nil {
+				return
+			}
+			result.Run, err = c.submitRunToolOutputs(ctx, threadID, result.Run, options.ToolHandlers)
+			if err != nil {
+				return
+			}
+			interval = options.InitialInterval
+		case RunStatusQueued, RunStatusInProgress, RunStatusCancelling:
+			interval = time.Duration(float64(interval) * options.Multiplier)
+			if interval > options.MaxInterval {
+				interval = options.MaxInterval
+			}
+		default:
+			err = fmt.Errorf("%w: %s", ErrRunPollUnknownStatus, result.Run.Status)
+			return
+		}
+	}
+}
+
+// CreateRunAndPoll creates a run and polls it with PollRun.
+func (c *Client) CreateRunAndPoll(
+	ctx context.Context,
+	threadID string,
+	request RunRequest,
+	options RunPollOptions,
+) (RunPollResult, error) {
+	run, err := c.CreateRun(ctx, threadID, request)
+	if err != nil {
+		return RunPollResult{Run: run}, err
+	}
+	return c.PollRun(ctx, threadID, run.ID, options)
+}
+
+func (o *RunPollOptions) setDefaults() {
+	if o.InitialInterval <= 0 {
+		o.InitialInterval = defaultRunPollInitialInterval
+	}
+	if o.MaxInterval <= 0 {
+		o.MaxInterval = defaultRunPollMaxInterval
+	}
+	if o.Multiplier < 1 {
+		o.Multiplier = defaultRunPollMultiplier
+	}
+}
+
+func (c *Client) submitRunToolOutputs(
+	ctx context.Context,
+	threadID string,
+	run Run,
+	handlers ToolHandlers,
+) (Run, error) {
+	if run.RequiredAction == nil || run.RequiredAction.SubmitToolOutputs == nil {
+		return run, nil
+	}
+
+	toolCalls := run.RequiredAction.SubmitToolOutputs.ToolCalls
+	request := SubmitToolOutputsRequest{ToolOutputs: make([]ToolOutput, 0, len(toolCalls))}
+	for _, toolCall := range toolCalls {
+		request.ToolOutputs = append(request.ToolOutputs, ToolOutput{
+			ToolCallID: toolCall.ID,
+			Output:     handlers.call(ctx, toolCall),
+		})
+	}
+	return c.SubmitToolOutputs(ctx, threadID, run.ID, request)
+}
+
+func (c *Client) listRunMessages(ctx context.Context, threadID, runID string) ([]Message, error) {
+	var (
+		messages []Message
+		after    *string
+	)
+	limit := runPollMessagesPageSize
+	order := "asc"
+	for {
+		list, err := c.ListMessage(ctx, threadID, &limit, &order, after, nil, &runID)
+		if err != nil {
+			return messages, err
+		}
+		messages = append(messages, list.Messages...)
+		if !list.HasMore || list.LastID == nil {
+			return messages, nil
+		}
+		after = list.LastID
+	}
+}
@@ -0,0 +1,135 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ibanyu/go-openai"
+	"github.com/ibanyu/go-openai/internal/test/checks"
+)
+
+func fastRunPollOptions(handlers openai.ToolHandlers) openai.RunPollOptions {
+	return openai.RunPollOptions{
+		InitialInterval: time.Millisecond,
+		MaxInterval:     2 * time.Millisecond,
+		ToolHandlers:    handlers,
+	}
+}
+
+func TestCreateRunAndPoll(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	polls := 0
+	var outputs openai.SubmitToolOutputsRequest
+	server.RegisterHandler("/v1/threads/thread_1/runs", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"id":"run_1","status":"queued"}`)
+	})
+	server.RegisterHandler("/v1/threads/thread_1/runs/run_1", func(w http.ResponseWriter, _ *http.Request) {
+		polls++
+		switch {
+		case polls == 1:
+			fmt.Fprint(w, `{"id":"run_1","status":"in_progress"}`)
+		case polls == 2:
+			fmt.Fprint(w, `{"id":"run_1","status":"requires_action","required_action":{"type":"submit_tool_outputs",`+
+				`"submit_tool_outputs":{"tool_calls":[`+
+				`{"id":"call_1","type":"function","function":{"name":"add","arguments":"{\"a\":1,\"b\":2}"}},`+
+				`{"id":"call_2","type":"function","function":{"name":"missing","arguments":"{}"}}]}}}`)
+		default:
+			fmt.Fprint(w, `{"id":"run_1","status":"completed"}`)
+		}
+	})
+	server.RegisterHandler("/v1/threads/thread_1/runs/run_1/submit_tool_outputs",
+		func(w http.ResponseWriter, r *http.Request) {
+			checks.NoError(t, json.NewDecoder(r.Body).Decode(&outputs), "decode request error")
+			fmt.Fprint(w, `{"id":"run_1","status":"queued"}`)
+		})
+	server.RegisterHandler("/v1/threads/thread_1/messages", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("run_id") != "run_1" || query.Get("order") != "asc" {
+			t.Errorf("unexpected query: %v", query)
+		}
+		if query.Get("after") == "" {
+			fmt.Fprint(w, `{"data":[{"id":"msg_1"}],"last_id":"msg_1","has_more":true}`)
+			return
+		}
+		fmt.Fprint(w, `{"data":[{"id":"msg_2"}],"last_id":"msg_2","has_more":false}`)
+	})
+
+	handlers := openai.ToolHandlers{
+		"add": func(_ context.Context, arguments string) (string, error) {
+			var args struct{ A, B int }
+			if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+				return "", err
+			}
+			return fmt.Sprint(args.A + args.B), nil
+		},
+	}
+	result, err := client.CreateRunAndPoll(context.Background(), "thread_1",
+		openai.RunRequest{AssistantID: "asst_1"}, fastRunPollOptions(handlers))
+	checks.NoError(t, err, "CreateRunAndPoll error")
+
+	if result.Run.Status != openai.RunStatusCompleted || polls != 3 {
+		t.Errorf("unexpected run %+v after %d polls", result.Run, polls)
+	}
+	if len(result.Messages) != 2 || result.Messages[1].ID != "msg_2" {
+		t.Errorf("unexpected messages: %+v", result.Messages)
+	}
+	if len(outputs.ToolOutputs) != 2 || outputs.ToolOutputs[0].Output != "3" {
+		t.Fatalf("unexpected tool outputs: %+v", outputs)
+	}
+	if output, _ := outputs.ToolOutputs[1].Output.(string); output == "" || output[0] != '{' {
+		t.Errorf("expected an error object for unknown tools, got %v", outputs.ToolOutputs[1].Output)
+	}
+}
+
+func TestPollRunReturnsOnRequiredActionWithoutHandlers(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	server.RegisterHandler("/v1/threads/thread_1/runs/run_1", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"id":"run_1","status":"requires_action"}`)
+	})
+
+	result, err := client.PollRun(context.Background(), "thread_1", "run_1", fastRunPollOptions(nil))
+	checks.NoError(t, err, "PollRun error")
+	if result.Run.Status != openai.RunStatusRequiresAction {
+		t.Errorf("unexpected run: %+v", result.Run)
+	}
+}
+
+func TestPollRunUnknownStatus(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	server.RegisterHandler("/v1/threads/thread_1/runs/run_1", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"id":"run_1","status":"paused"}`)
+	})
+
+	result, err := client.PollRun(context.Background(), "thread_1", "run_1", fastRunPollOptions(nil))
+	checks.ErrorIs(t, err, openai.ErrRunPollUnknownStatus, "expected ErrRunPollUnknownStatus")
+	if result.Run.Status != "paused" {
+		t.Errorf("unexpected run: %+v", result.Run)
+	}
+}
+
+func TestPollRunContextCancel(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	server.RegisterHandler("/v1/threads/thread_1/runs/run_1", func(w http.ResponseWriter, _ *http.Request) {
+		cancel()
+		fmt.Fprint(w, `{"id":"run_1","status":"in_progress"}`)
+	})
+
+	_, err := client.PollRun(ctx, "thread_1", "run_1", fastRunPollOptions(nil))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
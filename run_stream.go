@@ -0,0 +1,249 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	utils "github.com/ibanyu/go-openai/internal"
+)
+
+// AssistantStreamEventType is the name of a server-sent event of an assistant run stream.
+// https://platform.openai.com/docs/api-reference/assistants-streaming/events
+type AssistantStreamEventType string
+
+const (
+	AssistantStreamEventThreadCreated     AssistantStreamEventType = "thread.created"
+	AssistantStreamEventRunCreated        AssistantStreamEventType = "thread.run.created"
+	AssistantStreamEventRunQueued         AssistantStreamEventType = "thread.run.queued"
+	AssistantStreamEventRunInProgress     AssistantStreamEventType = "thread.run.in_progress"
+	AssistantStreamEventRunRequiresAction AssistantStreamEventType = "thread.run.requires_action"
+	AssistantStreamEventRunCompleted      AssistantStreamEventType = "thread.run.completed"
+	AssistantStreamEventRunIncomplete     AssistantStreamEventType = "thread.run.incomplete"
+	AssistantStreamEventRunFailed         AssistantStreamEventType = "thread.run.failed"
+	AssistantStreamEventRunCancelling     AssistantStreamEventType = "thread.run.cancelling"
+	AssistantStreamEventRunCancelled      AssistantStreamEventType = "thread.run.cancelled"
+	AssistantStreamEventRunExpired        AssistantStreamEventType = "thread.run.expired"
+	AssistantStreamEventRunStepCreated    AssistantStreamEventType = "thread.run.step.created"
+	AssistantStreamEventRunStepInProgress AssistantStreamEventType = "thread.run.step.in_progress"
+	AssistantStreamEventRunStepDelta      AssistantStreamEventType = "thread.run.step.delta"
+	AssistantStreamEventRunStepCompleted  AssistantStreamEventType = "thread.run.step.completed"
+	AssistantStreamEventRunStepFailed     AssistantStreamEventType = "thread.run.step.failed"
+	AssistantStreamEventRunStepCancelled  AssistantStreamEventType = "thread.run.step.cancelled"
+	AssistantStreamEventRunStepExpired    AssistantStreamEventType = "thread.run.step.expired"
+	AssistantStreamEventMessageCreated    AssistantStreamEventType = "thread.message.created"
+	AssistantStreamEventMessageInProgress AssistantStreamEventType = "thread.message.in_progress"
+	AssistantStreamEventMessageDelta      AssistantStreamEventType = "thread.message.delta"
+	AssistantStreamEventMessageCompleted  AssistantStreamEventType = "thread.message.completed"
+	AssistantStreamEventMessageIncomplete AssistantStreamEventType = "thread.message.incomplete"
+	AssistantStreamEventError             AssistantStreamEventType = "error"
+	AssistantStreamEventDone              AssistantStreamEventType = "done"
+)
+
+// MessageDelta is the payload of a thread.message.delta event.
+type MessageDelta struct {
+	ID     string              `json:"id"`
+	Object string              `json:"object"`
+	Delta  MessageDeltaContent `json:"delta"`
+}
+
+type MessageDeltaContent struct {
+	Role    string                `json:"role,omitempty"`
+	Content []MessageContentDelta `json:"content,omitempty"`
+}
+
+// MessageContentDelta is a part of a message that changed. Index identifies
+// the MessageContent of the message that the delta applies to.
+type MessageContentDelta struct {
+	Index     int          `json:"index"`
+	Type      string       `json:"type"`
+	Text      *MessageText `json:"text,omitempty"`
+	ImageFile *ImageFile   `json:"image_file,omitempty"`
+	ImageURL  *ImageURL    `json:"image_url,omitempty"`
+}
+
+// RunStepDelta is the payload of a thread.run.step.delta event.
+type RunStepDelta struct {
+	ID     string              `json:"id"`
+	Object string              `json:"object"`
+	Delta  RunStepDeltaContent `json:"delta"`
+}
+
+type RunStepDeltaContent struct {
+	StepDetails StepDetails `json:"step_details"`
+}
+
+// AssistantStreamEvent is a single event of an assistant run stream. Only the
+// field matching the kind of Event is set; Data always holds the raw payload.
+type AssistantStreamEvent struct {
+	Event AssistantStreamEventType
+	Data  json.RawMessage
+
+	Thread       *Thread
+	Run          *Run
+	RunStep      *RunStep
+	RunStepDelta *RunStepDelta
+	Message      *Message
+	MessageDelta *MessageDelta
+	Error        *APIError
+}
+
+// AssistantStream is a stream of events of an assistant run.
+type AssistantStream struct {
+	decoder     *utils.SSEDecoder
+	response    *http.Response
+	unmarshaler utils.Unmarshaler
+	isFinished  bool
+
+	httpHeader
+}
+
+// Recv returns the next event of the stream. It returns io.EOF after the done
+// event, and the APIError of an error event as the error.
+func (stream *AssistantStream) Recv() (event AssistantStreamEvent, err error) {
+	if stream.isFinished {
+		err = io.EOF
+		return
+	}
+
+	sse, err := stream.decoder.Next()
+	if err != nil {
+		return
+	}
+	event.Event = AssistantStreamEventType(sse.Event)
+	event.Data = bytes.TrimSpace(sse.Data)
+
+	if event.Event == AssistantStreamEventDone {
+		stream.isFinished = true
+		err = io.EOF
+		return
+	}
+	if event.Event == AssistantStreamEventError {
+		event.Error = stream.unmarshalError(event.Data)
+		err = event.Error
+		return
+	}
+
+	err = stream.unmarshalPayload(&event)
+	return
+}
+
+func (stream *AssistantStream) unmarshalPayload(event *AssistantStreamEvent) error {
+	var target any
+	name := string(event.Event)
+	switch {
+	case event.Event == AssistantStreamEventThreadCreated:
+		event.Thread = &Thread{}
+		target = event.Thread
+	case event.Event == AssistantStreamEventRunStepDelta:
+		event.RunStepDelta = &RunStepDelta{}
+		target = event.RunStepDelta
+	case event.Event == AssistantStreamEventMessageDelta:
+		event.MessageDelta = &MessageDelta{}
+		target = event.MessageDelta
+	case strings.HasPrefix(name, "thread.run.step."):
+		event.RunStep = &RunStep{}
+		target = event.RunStep
+	case strings.HasPrefix(name, "thread.run."):
+		event.Run = &Run{}
+		target = event.Run
+	case strings.HasPrefix(name, "thread.message."):
+		event.Message = &Message{}
+		target = event.Message
+	default:
+		// Unknown events are returned with their raw data only.
+		return nil
+	}
+	return stream.unmarshaler.Unmarshal(event.Data, target)
+}
+
+// unmarshalError decodes the payload of an error event, which is either an
+// error object or an error response wrapping one.
+func (stream *AssistantStream) unmarshalError(data []byte) *APIError {
+	var errResp ErrorResponse
+	if err := stream.unmarshaler.Unmarshal(data, &errResp); err == nil && errResp.Error != nil {
+		return errResp.Error
+	}
+	apiErr := &APIError{}
+	if err := stream.unmarshaler.Unmarshal(data, apiErr); err != nil || apiErr.Message == "" {
+		apiErr.Message = string(data)
+	}
+	return apiErr
+}
+
+// Close closes the underlying response body.
+func (stream *AssistantStream) Close() error {
+	return stream.response.Body.Close()
+}
+
+// CreateRunStream creates a new run and streams its events.
+func (c *Client) CreateRunStream(
+	ctx context.Context,
+	threadID string,
+	request RunRequest,
+) (stream *AssistantStream, err error) {
+	request.Stream = true
+	urlSuffix := fmt.Sprintf("/threads/%s/runs", threadID)
+	return c.sendAssistantStreamRequest(ctx, urlSuffix, request)
+}
+
+// CreateThreadAndRunStream creates a thread, runs it and streams the run events.
+func (c *Client) CreateThreadAndRunStream(
+	ctx context.Context,
+	request CreateThreadAndRunRequest,
+) (stream *AssistantStream, err error) {
+	request.Stream = true
+	return c.sendAssistantStreamRequest(ctx, "/threads/runs", request)
+}
+
+// SubmitToolOutputsStream submits tool outputs and streams the events of the resumed run.
+func (c *Client) SubmitToolOutputsStream(
+	ctx context.Context,
+	threadID string,
+	runID string,
+	request SubmitToolOutputsRequest,
+) (stream *AssistantStream, err error) {
+	request.Stream = true
+	urlSuffix := fmt.Sprintf("/threads/%s/runs/%s/submit_tool_outputs", threadID, runID)
+	return c.sendAssistantStreamRequest(ctx, urlSuffix, request)
+}
+
+func (c *Client) sendAssistantStreamRequest(
+	ctx context.Context,
+	urlSuffix string,
+	request any,
+) (*AssistantStream, error) {
+	req, err := c.newRequest(
+		ctx,
+		http.MethodPost,
+		c.fullURL(urlSuffix),
+		withBody(request),
+		withBetaAssistantVersion(c.config.AssistantVersion))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	req.Header.Set("Connection", "keep-alive")
+
+	resp, err := c.doRequest(req) //nolint:bodyclose // body is closed in stream.Close()
+	if err == nil && isFailureStatusCode(resp) {
+		err = c.handleErrorResp(resp)
+	}
+	c.runResponseHooks(req, resp, nil, err)
+	if err != nil {
+		return nil, err
+	}
+	c.withStreamTimeouts(resp)
+	return &AssistantStream{
+		decoder:     utils.NewSSEDecoder(bufio.NewReader(resp.Body)),
+		response:    resp,
+		unmarshaler: &utils.JSONUnmarshaler{},
+		httpHeader:  httpHeader(resp.Header),
+	}, nil
+}
@@ -0,0 +1,134 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/ibanyu/go-openai"
+	"github.com/ibanyu/go-openai/internal/test/checks"
+)
+
+func TestCreateRunStream(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	server.RegisterHandler("/v1/threads/thread_1/runs", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&body), "decode request error")
+		if body["stream"] != true || body["assistant_id"] != "asst_1" {
+			t.Errorf("unexpected request: %v", body)
+		}
+		if r.Header.Get("OpenAI-Beta") == "" {
+			t.Errorf("expected assistants beta header")
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: thread.run.created\n"+
+			`data: {"id":"run_1","object":"thread.run","status":"queued"}`+"\n\n")
+		fmt.Fprint(w, "event: thread.message.created\n"+
+			`data: {"id":"msg_1","object":"thread.message","role":"assistant","content":[]}`+"\n\n")
+		fmt.Fprint(w, "event: thread.message.delta\n"+
+			`data: {"id":"msg_1","object":"thread.message.delta",`+
+			`"delta":{"content":[{"index":0,"type":"text","text":{"value":"Hi"}}]}}`+"\n\n")
+		fmt.Fprint(w, "event: thread.run.step.delta\n"+
+			`data: {"id":"step_1","object":"thread.run.step.delta","delta":{"step_details":`+
+			`{"type":"tool_calls","tool_calls":[{"index":0,"id":"call_1","type":"function",`+
+			`"function":{"name":"f","arguments":"{}"}}]}}}`+"\n\n")
+		fmt.Fprint(w, "event: thread.run.requires_action\n"+
+			`data: {"id":"run_1","object":"thread.run","status":"requires_action","required_action":`+
+			`{"type":"submit_tool_outputs","submit_tool_outputs":{"tool_calls":[{"id":"call_1","type":"function"}]}}}`+
+			"\n\n")
+		fmt.Fprint(w, "event: done\ndata: [DONE]\n\n")
+	})
+
+	stream, err := client.CreateRunStream(context.Background(), "thread_1", openai.RunRequest{AssistantID: "asst_1"})
+	checks.NoError(t, err, "CreateRunStream error")
+	defer stream.Close()
+
+	var events []openai.AssistantStreamEvent
+	for {
+		event, recvErr := stream.Recv()
+		if errors.Is(recvErr, io.EOF) {
+			break
+		}
+		checks.NoErrorF(t, recvErr, "stream.Recv error")
+		events = append(events, event)
+	}
+
+	if len(events) != 5 {
+		t.Fatalf("expected 5 events, got %d", len(events))
+	}
+	if events[0].Run == nil || events[0].Run.ID != "run_1" || events[0].Run.Status != openai.RunStatusQueued {
+		t.Errorf("unexpected run event: %+v", events[0])
+	}
+	if events[1].Message == nil || events[1].Message.ID != "msg_1" {
+		t.Errorf("unexpected message event: %+v", events[1])
+	}
+	delta := events[2].MessageDelta
+	if delta == nil || delta.Delta.Content[0].Text == nil || delta.Delta.Content[0].Text.Value != "Hi" {
+		t.Errorf("unexpected message delta: %+v", events[2])
+	}
+	stepDelta := events[3].RunStepDelta
+	if stepDelta == nil || stepDelta.Delta.StepDetails.ToolCalls[0].Function.Name != "f" {
+		t.Errorf("unexpected step delta: %+v", events[3])
+	}
+	if events[4].Event != openai.AssistantStreamEventRunRequiresAction ||
+		events[4].Run.RequiredAction.SubmitToolOutputs.ToolCalls[0].ID != "call_1" {
+		t.Errorf("unexpected requires action event: %+v", events[4])
+	}
+}
+
+func TestSubmitToolOutputsStreamError(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	server.RegisterHandler("/v1/threads/thread_1/runs/run_1/submit_tool_outputs",
+		func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]any
+			checks.NoError(t, json.NewDecoder(r.Body).Decode(&body), "decode request error")
+			if body["stream"] != true {
+				t.Errorf("expected stream to be set: %v", body)
+			}
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "event: error\n"+`data: {"code":"server_error","message":"boom"}`+"\n\n")
+		})
+
+	stream, err := client.SubmitToolOutputsStream(context.Background(), "thread_1", "run_1",
+		openai.SubmitToolOutputsRequest{ToolOutputs: []openai.ToolOutput{{ToolCallID: "call_1", Output: "1"}}})
+	checks.NoError(t, err, "SubmitToolOutputsStream error")
+	defer stream.Close()
+
+	event, err := stream.Recv()
+	var apiErr *openai.APIError
+	if !errors.As(err, &apiErr) || apiErr.Message != "boom" || event.Event != openai.AssistantStreamEventError {
+		t.Fatalf("expected APIError event, got %+v %v", event, err)
+	}
+}
+
+func TestCreateThreadAndRunStream(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	server.RegisterHandler("/v1/threads/runs", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: thread.created\n"+`data: {"id":"thread_1","object":"thread"}`+"\n\n")
+	})
+
+	stream, err := client.CreateThreadAndRunStream(context.Background(), openai.CreateThreadAndRunRequest{
+		RunRequest: openai.RunRequest{AssistantID: "asst_1"},
+	})
+	checks.NoError(t, err, "CreateThreadAndRunStream error")
+	defer stream.Close()
+
+	event, err := stream.Recv()
+	checks.NoError(t, err, "stream.Recv error")
+	if event.Thread == nil || event.Thread.ID != "thread_1" {
+		t.Fatalf("unexpected thread event: %+v", event)
+	}
+	_, err = stream.Recv()
+	checks.ErrorIs(t, err, io.EOF, "expected io.EOF at end of stream")
+}
@@ -0,0 +1,148 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// SSEWriter writes server-sent events in the format of the OpenAI streaming
+// API, flushing after every event.
+type SSEWriter struct {
+	w             http.ResponseWriter
+	headerWritten bool
+}
+
+// NewSSEWriter returns a writer of server-sent events to w.
+func NewSSEWriter(w http.ResponseWriter) *SSEWriter {
+	return &SSEWriter{w: w}
+}
+
+// WriteData writes a data-only event.
+func (s *SSEWriter) WriteData(data []byte) error {
+	return s.writeEvent("", data)
+}
+
+// WriteJSON writes v, encoded as JSON, as a data-only event. Chunks keep their
+// RawExtensions.
+func (s *SSEWriter) WriteJSON(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.WriteData(data)
+}
+
+// WriteError writes err as an error event. An APIError is sent as is, other
+// errors as a server_error.
+func (s *SSEWriter) WriteError(err error) error {
+	apiErr := &APIError{}
+	if !errors.As(err, &apiErr) {
+		apiErr = &APIError{Message: err.Error(), Type: "server_error"}
+	}
+	data, err := json.Marshal(ErrorResponse{Error: apiErr})
+	if err != nil {
+		return err
+	}
+	return s.writeEvent("error", data)
+}
+
+// WriteDone writes the [DONE] message ending the stream.
+func (s *SSEWriter) WriteDone() error {
+	return s.WriteData([]byte("[DONE]"))
+}
+
+func (s *SSEWriter) writeEvent(event string, data []byte) error {
+	if !s.headerWritten {
+		header := s.w.Header()
+		header.Set("Content-Type", "text/event-stream")
+		header.Set("Cache-Control", "no-cache")
+		header.Set("Connection", "keep-alive")
+		s.headerWritten = true
+	}
+	if event != "" {
+		if _, err := fmt.Fprintf(s.w, "event: %s\n", event); err != nil {
+			return err
+		}
+	}
+	// Every line of data is written as a data field, which clients join with
+	// line breaks again.
+	var b bytes.Buffer
+	for _, line := range splitSSELines(data) {
+		b.WriteString("data: ")
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+	b.WriteByte('\n')
+	if _, err := s.w.Write(b.Bytes()); err != nil {
+		return err
+	}
+	if flusher, ok := s.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+// splitSSELines splits data on the line endings of server-sent events: CRLF,
+// LF and CR.
+func splitSSELines(data []byte) [][]byte {
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	data = bytes.ReplaceAll(data, []byte("\r"), []byte("\n"))
+	return bytes.Split(data, []byte("\n"))
+}
+
+// SSEForwardOptions configures ForwardSSE.
+type SSEForwardOptions struct {
+	// Raw forwards the payloads returned by RecvRaw without decoding and
+	// encoding them again.
+	Raw bool
+}
+
+// ForwardSSE writes the chunks of the stream to w as server-sent events,
+// then the [DONE] message, and closes the stream. An error of the stream is
+// forwarded as an error event and returned.
+//
+// Cancel ctx, such as the context of the request being served, when the
+// client disconnects; ForwardSSE then returns ctx.Err() without writing more.
+func (stream *streamReader[T]) ForwardSSE(ctx context.Context, w http.ResponseWriter, options SSEForwardOptions) error {
+	defer stream.Close()
+	stop := stream.closeOnDone(ctx)
+	defer stop()
+
+	writer := NewSSEWriter(w)
+	for {
+		data, err := stream.recvForward(options.Raw)
+		if err != nil && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if errors.Is(err, io.EOF) {
+			return writer.WriteDone()
+		}
+		if err != nil {
+			if writeErr := writer.WriteError(err); writeErr != nil {
+				return writeErr
+			}
+			return err
+		}
+		if err = writer.WriteData(data); err != nil {
+			return err
+		}
+	}
+}
+
+// recvForward returns the next payload of the stream, encoded again from
+// the decoded chunk unless raw is set.
+func (stream *streamReader[T]) recvForward(raw bool) ([]byte, error) {
+	if raw {
+		return stream.RecvRaw()
+	}
+	response, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(response)
+}
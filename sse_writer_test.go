@@ -0,0 +1,118 @@
+package openai_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ibanyu/go-openai"
+	utils "github.com/ibanyu/go-openai/internal"
+	"github.com/ibanyu/go-openai/internal/test/checks"
+)
+
+func TestChatCompletionStreamForwardSSE(t *testing.T) {
+	for _, raw := range []bool{false, true} {
+		t.Run(fmt.Sprintf("raw=%t", raw), func(t *testing.T) {
+			client, server, teardown := setupOpenAITestServer()
+			defer teardown()
+			server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				fmt.Fprint(w, `data: {"id":"1","provider":"p","choices":[{"index":0,"delta":{"content":"hi"}}]}`+"\n\n")
+				fmt.Fprint(w, "data: [DONE]\n\n")
+			})
+
+			recorder := httptest.NewRecorder()
+			err := createTestChatStream(t, client).ForwardSSE(context.Background(), recorder,
+				openai.SSEForwardOptions{Raw: raw})
+			checks.NoError(t, err, "ForwardSSE error")
+
+			if recorder.Header().Get("Content-Type") != "text/event-stream" || !recorder.Flushed {
+				t.Errorf("unexpected response: %v, flushed: %t", recorder.Header(), recorder.Flushed)
+			}
+			events := strings.Split(recorder.Body.String(), "\n\n")
+			if len(events) != 3 || events[1] != "data: [DONE]" || events[2] != "" {
+				t.Fatalf("unexpected events: %q", events)
+			}
+			for _, want := range []string{`"provider":"p"`, `"content":"hi"`} {
+				if !strings.HasPrefix(events[0], "data: {") || !strings.Contains(events[0], want) {
+					t.Errorf("expected %s in %q", want, events[0])
+				}
+			}
+		})
+	}
+}
+
+func TestChatCompletionStreamForwardSSEMultilineData(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"id\":\"1\",\n"+
+			"data:  \"choices\":[{\"index\":0,\"delta\":{\"content\":\"hi\"}}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	})
+
+	stream := createTestChatStream(t, client)
+	recorder := httptest.NewRecorder()
+	err := stream.ForwardSSE(context.Background(), recorder, openai.SSEForwardOptions{Raw: true})
+	checks.NoError(t, err, "ForwardSSE error")
+
+	decoder := utils.NewSSEDecoder(recorder.Body)
+	event, err := decoder.Next()
+	checks.NoError(t, err, "Next error")
+	want := "{\"id\":\"1\",\n \"choices\":[{\"index\":0,\"delta\":{\"content\":\"hi\"}}]}"
+	if string(event.Data) != want {
+		t.Errorf("unexpected data: %q", event.Data)
+	}
+	if event, err = decoder.Next(); err != nil || string(event.Data) != "[DONE]" {
+		t.Errorf("unexpected event: %q, %v", event.Data, err)
+	}
+}
+
+func TestChatCompletionStreamForwardSSEError(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: {"error":{"message":"overloaded","type":"server_error"}}`+"\n\n")
+	})
+
+	recorder := httptest.NewRecorder()
+	err := createTestChatStream(t, client).ForwardSSE(context.Background(), recorder, openai.SSEForwardOptions{})
+	apiErr := &openai.APIError{}
+	if !errors.As(err, &apiErr) || apiErr.Message != "overloaded" {
+		t.Fatalf("expected the upstream APIError, got %v", err)
+	}
+	want := `event: error` + "\n" + `data: {"error":{"message":"overloaded","type":"server_error"}}` + "\n\n"
+	if recorder.Body.String() != want {
+		t.Errorf("unexpected events: %q", recorder.Body.String())
+	}
+
+	recorder = httptest.NewRecorder()
+	checks.NoError(t, openai.NewSSEWriter(recorder).WriteError(errors.New("upstream hung up")), "WriteError error")
+	if !strings.Contains(recorder.Body.String(), `{"error":{"message":"upstream hung up","type":"server_error"}}`) {
+		t.Errorf("unexpected events: %q", recorder.Body.String())
+	}
+}
+
+func TestChatCompletionStreamForwardSSEDisconnect(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", stallingStreamHandler(0))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	recorder := httptest.NewRecorder()
+	err := createTestChatStream(t, client).ForwardSSE(ctx, recorder, openai.SSEForwardOptions{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if strings.Contains(recorder.Body.String(), "[DONE]") {
+		t.Errorf("unexpected events after the disconnect: %q", recorder.Body.String())
+	}
+}
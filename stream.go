@@ -23,7 +23,7 @@ func (c *Client) CreateCompletionStream(
 	request CompletionRequest,
 ) (stream *CompletionStream, err error) {
 	urlSuffix := "/completions"
-	if !checkEndpointSupportsModel(urlSuffix, request.Model) {
+	if !c.modelRegistry().supportsEndpoint(urlSuffix, request.Model) {
 		err = ErrCompletionUnsupportedModel
 		return
 	}
@@ -0,0 +1,68 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// Channel receives the stream in a goroutine and sends its chunks on the
+// returned channel, which is closed at the end of the stream. The error that
+// ended the stream, if any, is then sent on the error channel, which is closed
+// too. io.EOF is not reported.
+//
+// The stream is closed when the goroutine returns. Cancel ctx to stop
+// receiving early; ctx.Err() is then reported.
+func (stream *streamReader[T]) Channel(ctx context.Context) (<-chan T, <-chan error) {
+	chunks := make(chan T)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(chunks)
+		defer stream.Close()
+		stop := stream.closeOnDone(ctx)
+		defer stop()
+
+		for {
+			response, err := stream.recvContext(ctx)
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				errs <- err
+				return
+			}
+			select {
+			case chunks <- response:
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+		}
+	}()
+	return chunks, errs
+}
+
+// recvContext is Recv, returning ctx.Err() when the stream was closed because
+// ctx is done.
+func (stream *streamReader[T]) recvContext(ctx context.Context) (T, error) {
+	response, err := stream.Recv()
+	if err != nil && ctx.Err() != nil {
+		return response, ctx.Err()
+	}
+	return response, err
+}
+
+// closeOnDone closes the stream when ctx is done, which unblocks a pending
+// Recv, until stop is called.
+func (stream *streamReader[T]) closeOnDone(ctx context.Context) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			stream.Close()
+		case <-done:
+		}
+	}()
+	return func() { close(done) }
+}
@@ -0,0 +1,73 @@
+package openai_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ibanyu/go-openai"
+	"github.com/ibanyu/go-openai/internal/test/checks"
+)
+
+func chunkedStreamHandler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	for _, content := range []string{"a", "b", "c"} {
+		fmt.Fprintf(w, "data: {\"id\":\"1\",\"choices\":[{\"index\":0,\"delta\":{\"content\":%q}}]}\n\n", content)
+	}
+	fmt.Fprint(w, "data: [DONE]\n\n")
+}
+
+func createTestChatStream(t *testing.T, client *openai.Client) *openai.ChatCompletionStream {
+	t.Helper()
+	stream, err := client.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{
+		Model:    openai.GPT4o,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello"}},
+	})
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	return stream
+}
+
+func TestChatCompletionStreamChannel(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", chunkedStreamHandler)
+
+	chunks, errs := createTestChatStream(t, client).Channel(context.Background())
+	var content string
+	for chunk := range chunks {
+		content += chunk.Choices[0].Delta.Content
+	}
+	checks.NoError(t, <-errs, "stream error")
+	if content != "abc" {
+		t.Errorf("unexpected content: %q", content)
+	}
+}
+
+func TestChatCompletionStreamChannelCancel(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", stallingStreamHandler(0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	chunks, errs := createTestChatStream(t, client).Channel(ctx)
+	if chunk := <-chunks; chunk.Choices[0].Delta.Content != "hello" {
+		t.Errorf("unexpected chunk: %+v", chunk)
+	}
+
+	cancel()
+	select {
+	case err := <-errs:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("stream was not stopped by the cancellation")
+	}
+	if _, ok := <-chunks; ok {
+		t.Error("expected the chunk channel to be closed")
+	}
+}
@@ -0,0 +1,245 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+const defaultBroadcastBufferSize = 16
+
+var ErrStreamSubscriberDetached = errors.New("stream subscriber was detached for being too slow")
+
+// SlowSubscriberPolicy decides what a StreamBroadcaster does with a chunk
+// when the buffer of a subscriber is full.
+type SlowSubscriberPolicy int
+
+const (
+	// SlowSubscriberBlock waits for the subscriber, which slows down every
+	// other subscriber.
+	SlowSubscriberBlock SlowSubscriberPolicy = iota
+	// SlowSubscriberDrop skips the chunk for the subscriber.
+	SlowSubscriberDrop
+	// SlowSubscriberDetach stops sending chunks to the subscriber, whose Recv
+	// returns ErrStreamSubscriberDetached once its buffer is drained.
+	SlowSubscriberDetach
+)
+
+// StreamBroadcastOptions configures a StreamBroadcaster.
+type StreamBroadcastOptions struct {
+	// BufferSize is the number of chunks buffered for every subscriber.
+	// Defaults to 16.
+	BufferSize int
+	// Policy applies to subscribers whose buffer is full.
+	Policy SlowSubscriberPolicy
+}
+
+// StreamBroadcaster reads a stream once and sends every chunk to each of its
+// subscribers. Subscribers share the chunks and must not modify them.
+type StreamBroadcaster[T streamable] struct {
+	stream  *streamReader[T]
+	options StreamBroadcastOptions
+	cancel  context.CancelFunc
+	done    chan struct{}
+
+	mu          sync.Mutex
+	subscribers []*StreamSubscriber[T]
+	started     bool
+	finished    bool
+	err         error
+}
+
+// Broadcast returns a StreamBroadcaster reading the stream. Subscribe to it,
+// then call Start.
+func (stream *streamReader[T]) Broadcast(options StreamBroadcastOptions) *StreamBroadcaster[T] {
+	if options.BufferSize <= 0 {
+		options.BufferSize = defaultBroadcastBufferSize
+	}
+	return &StreamBroadcaster[T]{stream: stream, options: options, done: make(chan struct{})}
+}
+
+// Done returns a channel closed once the broadcaster stopped reading the
+// stream.
+func (b *StreamBroadcaster[T]) Done() <-chan struct{} {
+	return b.done
+}
+
+// Err returns the error that stopped the broadcaster, or nil while it runs
+// and when the stream ended normally.
+func (b *StreamBroadcaster[T]) Err() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if errors.Is(b.err, io.EOF) {
+		return nil
+	}
+	return b.err
+}
+
+// Subscribe adds a subscriber, which receives the chunks read after it was
+// added.
+func (b *StreamBroadcaster[T]) Subscribe() *StreamSubscriber[T] {
+	subscriber := &StreamSubscriber[T]{
+		chunks: make(chan T, b.options.BufferSize),
+		done:   make(chan struct{}),
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.finished {
+		subscriber.end(b.err)
+		return subscriber
+	}
+	b.subscribers = append(b.subscribers, subscriber)
+	return subscriber
+}
+
+// Start reads the stream in a goroutine until it ends, ctx is done or the
+// broadcaster is closed, then closes the stream. Only the first call has an
+// effect.
+func (b *StreamBroadcaster[T]) Start(ctx context.Context) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.started {
+		return
+	}
+	b.started = true
+	ctx, b.cancel = context.WithCancel(ctx)
+	go b.run(ctx)
+}
+
+// Close stops reading the stream. Subscribers receive context.Canceled.
+// Calling Close again has no effect.
+func (b *StreamBroadcaster[T]) Close() {
+	b.mu.Lock()
+	cancel, started := b.cancel, b.started
+	b.started = true
+	b.mu.Unlock()
+	if cancel != nil {
+		cancel()
+		return
+	}
+	if !started {
+		b.stream.Close()
+		b.finish(context.Canceled)
+	}
+}
+
+func (b *StreamBroadcaster[T]) run(ctx context.Context) {
+	defer b.cancel()
+	defer b.stream.Close()
+	stop := b.stream.closeOnDone(ctx)
+	defer stop()
+
+	for {
+		response, err := b.stream.recvContext(ctx)
+		if err != nil {
+			b.finish(err)
+			return
+		}
+		b.mu.Lock()
+		subscribers := append([]*StreamSubscriber[T](nil), b.subscribers...)
+		b.mu.Unlock()
+		for _, subscriber := range subscribers {
+			if err = b.send(ctx, subscriber, response); err != nil {
+				b.finish(err)
+				return
+			}
+		}
+	}
+}
+
+// send sends a chunk to a subscriber according to the policy. It only fails
+// when ctx is done while blocked on the subscriber.
+func (b *StreamBroadcaster[T]) send(ctx context.Context, subscriber *StreamSubscriber[T], response T) error {
+	select {
+	case <-subscriber.done:
+		b.remove(subscriber, nil)
+		return nil
+	case subscriber.chunks <- response:
+		return nil
+	default:
+	}
+
+	switch b.options.Policy {
+	case SlowSubscriberBlock:
+		select {
+		case <-subscriber.done:
+			b.remove(subscriber, nil)
+		case subscriber.chunks <- response:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	case SlowSubscriberDrop:
+		atomic.AddInt64(&subscriber.dropped, 1)
+	case SlowSubscriberDetach:
+		b.remove(subscriber, ErrStreamSubscriberDetached)
+	}
+	return nil
+}
+
+// remove stops sending chunks to a subscriber and ends it with err.
+func (b *StreamBroadcaster[T]) remove(subscriber *StreamSubscriber[T], err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, s := range b.subscribers {
+		if s == subscriber {
+			b.subscribers = append(b.subscribers[:i], b.subscribers[i+1:]...)
+			subscriber.end(err)
+			return
+		}
+	}
+}
+
+// finish ends every subscriber with err.
+func (b *StreamBroadcaster[T]) finish(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.finished = true
+	b.err = err
+	for _, subscriber := range b.subscribers {
+		subscriber.end(err)
+	}
+	b.subscribers = nil
+	close(b.done)
+}
+
+// StreamSubscriber receives the chunks of a StreamBroadcaster.
+type StreamSubscriber[T streamable] struct {
+	chunks    chan T
+	done      chan struct{}
+	closeOnce sync.Once
+	err       error
+	dropped   int64
+}
+
+// Recv returns the next chunk. At the end of the stream, it returns io.EOF
+// or the error that ended it.
+func (s *StreamSubscriber[T]) Recv() (response T, err error) {
+	response, ok := <-s.chunks
+	if !ok {
+		err = s.err
+	}
+	return
+}
+
+// Close unsubscribes, so that the broadcaster no longer waits for the
+// subscriber. Recv returns io.EOF once the broadcaster has noticed it, at
+// the latest with the next chunk.
+func (s *StreamSubscriber[T]) Close() {
+	s.closeOnce.Do(func() { close(s.done) })
+}
+
+// Dropped returns the number of chunks dropped by SlowSubscriberDrop.
+func (s *StreamSubscriber[T]) Dropped() int {
+	return int(atomic.LoadInt64(&s.dropped))
+}
+
+// end makes Recv return err, or io.EOF when nil, after the buffered chunks.
+func (s *StreamSubscriber[T]) end(err error) {
+	if err == nil {
+		err = io.EOF
+	}
+	s.err = err
+	close(s.chunks)
+}
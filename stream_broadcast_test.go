@@ -0,0 +1,147 @@
+package openai_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ibanyu/go-openai"
+	"github.com/ibanyu/go-openai/internal/test/checks"
+)
+
+// readSubscriber returns the content received by subscriber and the error
+// that ended it.
+func readSubscriber(subscriber *openai.StreamSubscriber[openai.ChatCompletionStreamResponse]) (string, error) {
+	var content string
+	for {
+		response, err := subscriber.Recv()
+		if err != nil {
+			return content, err
+		}
+		content += response.Choices[0].Delta.Content
+	}
+}
+
+func TestStreamBroadcastSlowSubscriber(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      openai.SlowSubscriberPolicy
+		wantContent string
+		wantErr     error
+		wantDropped int
+	}{
+		{"drop", openai.SlowSubscriberDrop, "a", io.EOF, 2},
+		{"detach", openai.SlowSubscriberDetach, "a", openai.ErrStreamSubscriberDetached, 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client, server, teardown := setupOpenAITestServer()
+			defer teardown()
+			server.RegisterHandler("/v1/chat/completions", chunkedStreamHandler)
+
+			broadcaster := createTestChatStream(t, client).Broadcast(openai.StreamBroadcastOptions{
+				BufferSize: 1,
+				Policy:     tc.policy,
+			})
+			subscriber := broadcaster.Subscribe()
+			broadcaster.Start(context.Background())
+
+			// The subscriber only reads once the stream ended, so its buffer
+			// is full for every chunk but the first.
+			<-broadcaster.Done()
+			checks.NoError(t, broadcaster.Err(), "broadcast error")
+			content, err := readSubscriber(subscriber)
+			if content != tc.wantContent || !errors.Is(err, tc.wantErr) || subscriber.Dropped() != tc.wantDropped {
+				t.Errorf("unexpected subscriber result: %q, %v, %d dropped", content, err, subscriber.Dropped())
+			}
+		})
+	}
+}
+
+func TestStreamBroadcastBlock(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", chunkedStreamHandler)
+
+	broadcaster := createTestChatStream(t, client).Broadcast(openai.StreamBroadcastOptions{BufferSize: 1})
+	subscribers := []*openai.StreamSubscriber[openai.ChatCompletionStreamResponse]{
+		broadcaster.Subscribe(), broadcaster.Subscribe(), broadcaster.Subscribe(),
+	}
+	broadcaster.Start(context.Background())
+	subscribers[2].Close()
+
+	results := make(chan string, 2)
+	for _, subscriber := range subscribers[:2] {
+		go func(subscriber *openai.StreamSubscriber[openai.ChatCompletionStreamResponse]) {
+			content, err := readSubscriber(subscriber)
+			if !errors.Is(err, io.EOF) {
+				content = err.Error()
+			}
+			results <- content
+		}(subscriber)
+	}
+	for i := 0; i < 2; i++ {
+		if content := <-results; content != "abc" {
+			t.Errorf("unexpected content: %q", content)
+		}
+	}
+
+	late := broadcaster.Subscribe()
+	if _, err := late.Recv(); !errors.Is(err, io.EOF) {
+		t.Errorf("expected io.EOF for a subscriber added after the end, got %v", err)
+	}
+}
+
+func TestStreamBroadcastClose(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", stallingStreamHandler(0))
+
+	broadcaster := createTestChatStream(t, client).Broadcast(openai.StreamBroadcastOptions{})
+	subscriber := broadcaster.Subscribe()
+	broadcaster.Start(context.Background())
+	if response, err := subscriber.Recv(); err != nil || response.Choices[0].Delta.Content != "hello" {
+		t.Fatalf("unexpected chunk: %+v, %v", response, err)
+	}
+
+	broadcaster.Close()
+	done := make(chan error, 1)
+	go func() {
+		_, err := subscriber.Recv()
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("closing the broadcaster did not end the subscriber")
+	}
+}
+
+func TestStreamBroadcastCloseTwice(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", chunkedStreamHandler)
+
+	// Without Start.
+	broadcaster := createTestChatStream(t, client).Broadcast(openai.StreamBroadcastOptions{})
+	subscriber := broadcaster.Subscribe()
+	broadcaster.Close()
+	broadcaster.Close()
+	broadcaster.Start(context.Background())
+	<-broadcaster.Done()
+	if _, err := subscriber.Recv(); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	// After Start.
+	broadcaster = createTestChatStream(t, client).Broadcast(openai.StreamBroadcastOptions{})
+	broadcaster.Start(context.Background())
+	broadcaster.Close()
+	broadcaster.Close()
+	<-broadcaster.Done()
+}
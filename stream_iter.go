@@ -0,0 +1,39 @@
+//go:build go1.23
+
+package openai
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+)
+
+// All returns an iterator over the chunks of the stream. An error that ends
+// the stream, other than io.EOF, is yielded last with a zero chunk.
+//
+// The stream is closed when the iteration ends, including when the loop
+// breaks early. Cancel ctx to stop a pending receive; ctx.Err() is then
+// yielded.
+func (stream *streamReader[T]) All(ctx context.Context) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		defer stream.Close()
+		stop := stream.closeOnDone(ctx)
+		defer stop()
+
+		for {
+			response, err := stream.recvContext(ctx)
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			if !yield(response, nil) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+//go:build go1.23
+
+package openai_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestChatCompletionStreamAll(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", chunkedStreamHandler)
+
+	var content string
+	for chunk, err := range createTestChatStream(t, client).All(context.Background()) {
+		if err != nil {
+			t.Fatalf("stream error: %v", err)
+		}
+		content += chunk.Choices[0].Delta.Content
+	}
+	if content != "abc" {
+		t.Errorf("unexpected content: %q", content)
+	}
+}
+
+func TestChatCompletionStreamAllClose(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	disconnected := make(chan struct{})
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		stallingStreamHandler(0)(w, r)
+		close(disconnected)
+	})
+
+	for range createTestChatStream(t, client).All(context.Background()) {
+		break
+	}
+	select {
+	case <-disconnected:
+	case <-time.After(time.Second):
+		t.Fatal("breaking the loop did not close the stream")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	disconnected = make(chan struct{})
+	var last error
+	for _, err := range createTestChatStream(t, client).All(ctx) {
+		last = err
+	}
+	if !errors.Is(last, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", last)
+	}
+}
@@ -3,6 +3,7 @@ package openai
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -10,10 +11,7 @@ import (
 	utils "github.com/ibanyu/go-openai/internal"
 )
 
-var (
-	headerData  = []byte("data: ")
-	errorPrefix = []byte(`data: {"error":`)
-)
+var errorPrefix = []byte(`{"error":`)
 
 type streamable interface {
 	ChatCompletionStreamResponse | CompletionResponse
@@ -21,12 +19,22 @@ type streamable interface {
 
 type streamReader[T streamable] struct {
 	emptyMessagesLimit uint
+	emptyMessagesCount uint
 	isFinished         bool
 
 	reader         *bufio.Reader
+	decoder        *utils.SSEDecoder
 	response       *http.Response
 	errAccumulator utils.ErrorAccumulator
 	unmarshaler    utils.Unmarshaler
+	// lineDelimited streams send one JSON payload per line instead of server-sent events.
+	lineDelimited bool
+	// transform, when set, rewrites every received payload into the format of T.
+	// It returns nil to skip a payload and io.EOF to finish the stream.
+	transform func(data []byte) ([]byte, error)
+
+	event   string
+	eventID string
 
 	httpHeader
 }
@@ -49,53 +57,116 @@ func (stream *streamReader[T]) RecvRaw() ([]byte, error) {
 		return nil, io.EOF
 	}
 
-	return stream.processLines()
+	for {
+		var rawLine []byte
+		var err error
+		if stream.lineDelimited {
+			rawLine, err = stream.processJSONLines()
+		} else {
+			rawLine, err = stream.processLines()
+		}
+		if err != nil || stream.transform == nil {
+			return rawLine, err
+		}
+
+		rawLine, err = stream.transform(rawLine)
+		if errors.Is(err, io.EOF) {
+			stream.isFinished = true
+		}
+		if err != nil || rawLine != nil {
+			return rawLine, err
+		}
+	}
 }
 
-//nolint:gocognit
+// processLines returns the data of the next server-sent event.
 func (stream *streamReader[T]) processLines() ([]byte, error) {
-	var (
-		emptyMessagesCount uint
-		hasErrorPrefix     bool
-	)
+	if stream.decoder == nil {
+		stream.decoder = utils.NewSSEDecoder(stream.reader)
+		stream.decoder.OnLine = stream.processLine
+	}
 
-	for {
-		rawLine, readErr := stream.reader.ReadBytes('\n')
-		if readErr != nil || hasErrorPrefix {
-			respErr := stream.unmarshalError()
-			if respErr != nil {
+	event, readErr := stream.decoder.Next()
+	if readErr != nil {
+		if errors.Is(readErr, io.EOF) || errors.Is(readErr, io.ErrUnexpectedEOF) {
+			if respErr := stream.unmarshalError(); respErr != nil {
 				return nil, fmt.Errorf("error, %w", respErr.Error)
 			}
-			return nil, readErr
 		}
+		return nil, readErr
+	}
+	stream.event = event.Event
+	stream.eventID = event.ID
 
-		noSpaceLine := bytes.TrimSpace(rawLine)
-		if bytes.HasPrefix(noSpaceLine, errorPrefix) {
-			hasErrorPrefix = true
+	data := bytes.TrimSpace(event.Data)
+	if bytes.HasPrefix(data, errorPrefix) {
+		if writeErr := stream.errAccumulator.Write(data); writeErr != nil {
+			return nil, writeErr
 		}
-		if !bytes.HasPrefix(noSpaceLine, headerData) || hasErrorPrefix {
-			if hasErrorPrefix {
-				noSpaceLine = bytes.TrimPrefix(noSpaceLine, headerData)
-			}
-			writeErr := stream.errAccumulator.Write(noSpaceLine)
-			if writeErr != nil {
-				return nil, writeErr
-			}
-			emptyMessagesCount++
-			if emptyMessagesCount > stream.emptyMessagesLimit {
-				return nil, ErrTooManyEmptyStreamMessages
-			}
-
-			continue
+		if respErr := stream.unmarshalError(); respErr != nil {
+			return nil, fmt.Errorf("error, %w", respErr.Error)
 		}
+	}
+	if string(data) == "[DONE]" {
+		stream.isFinished = true
+		return nil, io.EOF
+	}
+	return data, nil
+}
 
-		noPrefixLine := bytes.TrimPrefix(noSpaceLine, headerData)
-		if string(noPrefixLine) == "[DONE]" {
-			stream.isFinished = true
-			return nil, io.EOF
+// processLine is called for every line of the event stream. Lines that are
+// not part of an event are collected as a possible error response, and too
+// many consecutive lines without data, empty data fields included, abort the
+// stream.
+func (stream *streamReader[T]) processLine(field string, line []byte) error {
+	switch field {
+	case "data":
+		if _, value, _ := bytes.Cut(line, []byte(":")); len(bytes.TrimSpace(value)) > 0 {
+			stream.emptyMessagesCount = 0
+			return nil
+		}
+	case "event", "id", "retry":
+	default:
+		if !bytes.HasPrefix(line, []byte(":")) {
+			if writeErr := stream.errAccumulator.Write(bytes.TrimSpace(line)); writeErr != nil {
+				return writeErr
+			}
 		}
+	}
+
+	stream.emptyMessagesCount++
+	if stream.emptyMessagesCount > stream.emptyMessagesLimit {
+		return ErrTooManyEmptyStreamMessages
+	}
+	return nil
+}
 
-		return noPrefixLine, nil
+// Event returns the event type of the last received server-sent event.
+func (stream *streamReader[T]) Event() string {
+	return stream.event
+}
+
+// LastEventID returns the ID of the last received server-sent event.
+func (stream *streamReader[T]) LastEventID() string {
+	return stream.eventID
+}
+
+// processJSONLines returns the next non-empty line of a newline delimited JSON stream.
+func (stream *streamReader[T]) processJSONLines() ([]byte, error) {
+	var emptyMessagesCount uint
+	for {
+		rawLine, readErr := stream.reader.ReadBytes('\n')
+		line := bytes.TrimSpace(rawLine)
+		if len(line) > 0 {
+			return line, nil
+		}
+		if readErr != nil {
+			return nil, readErr
+		}
+		emptyMessagesCount++
+		if emptyMessagesCount > stream.emptyMessagesLimit {
+			return nil, ErrTooManyEmptyStreamMessages
+		}
 	}
 }
 
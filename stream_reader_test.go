@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"errors"
+	"io"
 	"testing"
 
 	utils "github.com/ibanyu/go-openai/internal"
@@ -76,3 +77,21 @@ func TestStreamReaderRecvRaw(t *testing.T) {
 		t.Fatalf("Did not return raw line: %v", string(rawLine))
 	}
 }
+
+func TestStreamReaderNamedEvents(t *testing.T) {
+	stream := &streamReader[ChatCompletionStreamResponse]{
+		emptyMessagesLimit: 3,
+		reader: bufio.NewReader(bytes.NewReader([]byte(
+			"event: message_start\r\nid: 7\r\ndata:{\"a\":\r\ndata: 1}\r\n\r\n: ping\r\n\r\ndata: [DONE]\r\n\r\n",
+		))),
+		errAccumulator: utils.NewErrorAccumulator(),
+		unmarshaler:    &utils.JSONUnmarshaler{},
+	}
+	rawLine, err := stream.RecvRaw()
+	checks.NoError(t, err, "RecvRaw error")
+	if string(rawLine) != "{\"a\":\n1}" || stream.Event() != "message_start" || stream.LastEventID() != "7" {
+		t.Fatalf("unexpected event %q %q: %q", stream.Event(), stream.LastEventID(), rawLine)
+	}
+	_, err = stream.RecvRaw()
+	checks.ErrorIs(t, err, io.EOF, "expected io.EOF after [DONE]")
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
@@ -225,6 +226,36 @@ func TestCreateCompletionStreamTooManyEmptyStreamMessagesError(t *testing.T) {
 	}
 }
 
+func TestCreateChatCompletionStreamEmptyDataKeepAlive(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, content := range []string{"a", "b"} {
+			fmt.Fprint(w, "data: \n\n")
+			fmt.Fprintf(w, "data: {\"id\":\"1\",\"choices\":[{\"index\":0,\"delta\":{\"content\":%q}}]}\n\n", content)
+		}
+		fmt.Fprint(w, "data: \n\ndata: [DONE]\n\n")
+	})
+
+	stream := createTestChatStream(t, client)
+	defer stream.Close()
+	var content string
+	for {
+		response, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv error: %v", err)
+		}
+		content += response.Choices[0].Delta.Content
+	}
+	if content != "ab" {
+		t.Errorf("unexpected content: %q", content)
+	}
+}
+
 func TestCreateCompletionStreamUnexpectedTerminatedError(t *testing.T) {
 	client, server, teardown := setupOpenAITestServer()
 	defer teardown()
@@ -0,0 +1,91 @@
+package openai
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	ErrStreamIdleTimeout = errors.New("stream received no data within the idle timeout")
+	ErrStreamTimeout     = errors.New("stream did not finish within the timeout")
+)
+
+// withStreamTimeouts makes the body of resp close itself when the stream
+// times out, as configured by StreamIdleTimeout and StreamTimeout.
+func (c *Client) withStreamTimeouts(resp *http.Response) {
+	if c.config.StreamIdleTimeout <= 0 && c.config.StreamTimeout <= 0 {
+		return
+	}
+	resp.Body = newTimeoutBody(resp.Body, c.config.StreamIdleTimeout, c.config.StreamTimeout)
+}
+
+// timeoutBody closes a response body that receives no data for idleTimeout
+// while a read is waiting for it, or that is still open after timeout, so
+// that reads blocked on a stalled stream return the timeout error.
+type timeoutBody struct {
+	body        io.ReadCloser
+	idleTimeout time.Duration
+	idleTimer   *time.Timer
+	timer       *time.Timer
+
+	mu  sync.Mutex
+	err error
+}
+
+func newTimeoutBody(body io.ReadCloser, idleTimeout, timeout time.Duration) *timeoutBody {
+	b := &timeoutBody{body: body, idleTimeout: idleTimeout}
+	if idleTimeout > 0 {
+		// The idle timer only runs during reads, so that the time a slow
+		// consumer spends between them is not taken for upstream silence.
+		b.idleTimer = time.AfterFunc(idleTimeout, func() { b.expire(ErrStreamIdleTimeout) })
+		b.idleTimer.Stop()
+	}
+	if timeout > 0 {
+		b.timer = time.AfterFunc(timeout, func() { b.expire(ErrStreamTimeout) })
+	}
+	return b
+}
+
+// Read reads from the body. It fails with ErrStreamIdleTimeout when no data,
+// heartbeat comments included, arrives within the idle timeout.
+func (b *timeoutBody) Read(p []byte) (int, error) {
+	if b.idleTimer != nil {
+		b.idleTimer.Reset(b.idleTimeout)
+	}
+	n, err := b.body.Read(p)
+	if b.idleTimer != nil {
+		b.idleTimer.Stop()
+	}
+	if timeoutErr := b.timeoutErr(); timeoutErr != nil {
+		return n, timeoutErr
+	}
+	return n, err
+}
+
+func (b *timeoutBody) Close() error {
+	if b.idleTimer != nil {
+		b.idleTimer.Stop()
+	}
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	return b.body.Close()
+}
+
+func (b *timeoutBody) expire(err error) {
+	b.mu.Lock()
+	if b.err == nil {
+		b.err = err
+	}
+	b.mu.Unlock()
+	_ = b.body.Close()
+}
+
+func (b *timeoutBody) timeoutErr() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.err
+}
@@ -0,0 +1,117 @@
+package openai_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ibanyu/go-openai"
+	"github.com/ibanyu/go-openai/internal/test/checks"
+)
+
+// stallingStreamHandler sends a chunk, then a heartbeat comment every
+// heartbeat, or nothing when heartbeat is zero, until the client disconnects.
+func stallingStreamHandler(heartbeat time.Duration) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: {"id":"1","choices":[{"index":0,"delta":{"content":"hello"}}]}`+"\n\n")
+		w.(http.Flusher).Flush()
+
+		var ticks <-chan time.Time
+		if heartbeat > 0 {
+			ticker := time.NewTicker(heartbeat)
+			defer ticker.Stop()
+			ticks = ticker.C
+		}
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-ticks:
+				fmt.Fprint(w, ": ping\n\n")
+				w.(http.Flusher).Flush()
+			}
+		}
+	}
+}
+
+func TestChatCompletionStreamTimeouts(t *testing.T) {
+	tests := []struct {
+		name      string
+		heartbeat time.Duration
+		idle      time.Duration
+		total     time.Duration
+		want      error
+	}{
+		{"idle", 0, 50 * time.Millisecond, 0, openai.ErrStreamIdleTimeout},
+		{"heartbeats keep the stream alive", 10 * time.Millisecond, 50 * time.Millisecond, 200 * time.Millisecond,
+			openai.ErrStreamTimeout},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+				config.StreamIdleTimeout = tc.idle
+				config.StreamTimeout = tc.total
+			})
+			defer teardown()
+			server.RegisterHandler("/v1/chat/completions", stallingStreamHandler(tc.heartbeat))
+
+			stream := createTestChatStream(t, client)
+			defer stream.Close()
+
+			response, err := stream.Recv()
+			checks.NoError(t, err, "Recv error")
+			if response.Choices[0].Delta.Content != "hello" {
+				t.Errorf("unexpected response: %+v", response)
+			}
+
+			start := time.Now()
+			_, err = stream.Recv()
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("timeout took %v", elapsed)
+			}
+		})
+	}
+}
+
+// TestChatCompletionStreamIdleTimeoutSlowConsumer checks that the time spent
+// between calls to Recv does not count as upstream silence.
+func TestChatCompletionStreamIdleTimeoutSlowConsumer(t *testing.T) {
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.StreamIdleTimeout = 30 * time.Millisecond
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, content := range []string{"a", "b", "c"} {
+			fmt.Fprintf(w, "data: {\"id\":\"1\",\"choices\":[{\"index\":0,\"delta\":{\"content\":%q}}]}\n\n", content)
+			w.(http.Flusher).Flush()
+			time.Sleep(5 * time.Millisecond)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	})
+
+	stream := createTestChatStream(t, client)
+	defer stream.Close()
+	var content string
+	for {
+		response, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv error: %v", err)
+		}
+		content += response.Choices[0].Delta.Content
+		time.Sleep(100 * time.Millisecond)
+	}
+	if content != "abc" {
+		t.Errorf("unexpected content: %q", content)
+	}
+}
@@ -0,0 +1,90 @@
+package openai
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ibanyu/go-openai/jsonschema"
+)
+
+// StrictSchemaMode controls what the client does with the schemas of strict
+// response formats and functions before sending a chat completion request.
+type StrictSchemaMode int
+
+const (
+	// StrictSchemaModeOff sends strict schemas as they are.
+	StrictSchemaModeOff StrictSchemaMode = iota
+	// StrictSchemaModeCheck fails requests whose strict schemas do not follow
+	// the strict mode rules, with jsonschema.StrictSchemaErrors.
+	StrictSchemaModeCheck
+	// StrictSchemaModeTransform rewrites strict schemas with
+	// jsonschema.MakeStrict, then checks them as StrictSchemaModeCheck does.
+	StrictSchemaModeTransform
+)
+
+// applyStrictSchemaMode checks or rewrites the strict schemas of request. The
+// schemas are replaced in copies, leaving the caller's values untouched.
+func (c *Client) applyStrictSchemaMode(request *ChatCompletionRequest) error {
+	mode := c.config.StrictSchemaMode
+	if mode == StrictSchemaModeOff {
+		return nil
+	}
+
+	if format := request.ResponseFormat; format != nil && format.JSONSchema != nil && format.JSONSchema.Strict {
+		schema, err := strictSchema(mode, format.JSONSchema.Schema)
+		if err != nil {
+			return fmt.Errorf("invalid strict schema of response format %q: %w", format.JSONSchema.Name, err)
+		}
+		jsonSchema := *format.JSONSchema
+		jsonSchema.Schema, _ = schema.(json.Marshaler)
+		request.ResponseFormat = &ChatCompletionResponseFormat{Type: format.Type, JSONSchema: &jsonSchema}
+	}
+
+	tools := append([]Tool(nil), request.Tools...)
+	for i, tool := range tools {
+		if tool.Function == nil || !tool.Function.Strict {
+			continue
+		}
+		function, err := strictFunction(mode, *tool.Function)
+		if err != nil {
+			return err
+		}
+		tools[i].Function = &function
+	}
+	request.Tools = tools
+
+	functions := append([]FunctionDefinition(nil), request.Functions...)
+	for i, function := range functions {
+		if !function.Strict {
+			continue
+		}
+		var err error
+		if functions[i], err = strictFunction(mode, function); err != nil {
+			return err
+		}
+	}
+	request.Functions = functions
+	return nil
+}
+
+func strictFunction(mode StrictSchemaMode, function FunctionDefinition) (FunctionDefinition, error) {
+	parameters, err := strictSchema(mode, function.Parameters)
+	if err != nil {
+		return function, fmt.Errorf("invalid strict schema of function %q: %w", function.Name, err)
+	}
+	function.Parameters = parameters
+	return function, nil
+}
+
+// strictSchema returns schema, rewritten in StrictSchemaModeTransform, once it
+// follows the strict mode rules.
+func strictSchema(mode StrictSchemaMode, schema any) (any, error) {
+	if mode == StrictSchemaModeTransform {
+		strict, err := jsonschema.MakeStrict(schema)
+		if err != nil {
+			return nil, err
+		}
+		schema = strict
+	}
+	return schema, jsonschema.CheckStrict(schema)
+}
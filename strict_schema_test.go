@@ -0,0 +1,98 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ibanyu/go-openai"
+	"github.com/ibanyu/go-openai/internal/test/checks"
+	"github.com/ibanyu/go-openai/jsonschema"
+)
+
+func strictSchemaRequest() openai.ChatCompletionRequest {
+	parameters := &jsonschema.Definition{
+		Type: jsonschema.Object,
+		Properties: map[string]jsonschema.Definition{
+			"city": {Type: jsonschema.String},
+			"unit": {Type: jsonschema.String, Enum: []string{"celsius", "fahrenheit"}},
+		},
+		Required: []string{"city"},
+	}
+	return openai.ChatCompletionRequest{
+		Model:    openai.GPT4o,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello!"}},
+		Tools: []openai.Tool{
+			{Type: openai.ToolTypeFunction, Function: &openai.FunctionDefinition{
+				Name: "get_weather", Strict: true, Parameters: parameters,
+			}},
+			{Type: openai.ToolTypeFunction, Function: &openai.FunctionDefinition{
+				Name: "loose", Parameters: json.RawMessage(`{"type":"object"}`),
+			}},
+		},
+	}
+}
+
+func TestStrictSchemaModeCheck(t *testing.T) {
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.StrictSchemaMode = openai.StrictSchemaModeCheck
+	})
+	defer teardown()
+
+	requests := 0
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		fmt.Fprint(w, `{"id":"1","choices":[]}`)
+	})
+
+	_, err := client.CreateChatCompletion(context.Background(), strictSchemaRequest())
+	var errs jsonschema.StrictSchemaErrors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("expected strict schema errors, got %v", err)
+	}
+	if requests != 0 {
+		t.Errorf("expected the request not to be sent")
+	}
+
+	_, err = client.CreateChatCompletionStream(context.Background(), strictSchemaRequest())
+	if !errors.As(err, &errs) {
+		t.Errorf("expected strict schema errors for streams, got %v", err)
+	}
+}
+
+func TestStrictSchemaModeTransform(t *testing.T) {
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.StrictSchemaMode = openai.StrictSchemaModeTransform
+	})
+	defer teardown()
+
+	var sent struct {
+		Tools []struct {
+			Function struct {
+				Parameters map[string]any `json:"parameters"`
+			} `json:"function"`
+		} `json:"tools"`
+	}
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&sent), "decode request error")
+		fmt.Fprint(w, `{"id":"1","choices":[]}`)
+	})
+
+	request := strictSchemaRequest()
+	_, err := client.CreateChatCompletion(context.Background(), request)
+	checks.NoError(t, err, "CreateChatCompletion error")
+
+	parameters := sent.Tools[0].Function.Parameters
+	if required, _ := parameters["required"].([]any); len(required) != 2 || parameters["additionalProperties"] != false {
+		t.Errorf("expected a strict schema to be sent, got %v", parameters)
+	}
+	if _, ok := sent.Tools[1].Function.Parameters["additionalProperties"]; ok {
+		t.Errorf("expected non strict schemas to be sent as they are, got %v", sent.Tools[1].Function.Parameters)
+	}
+	if definition, _ := request.Tools[0].Function.Parameters.(*jsonschema.Definition); len(definition.Required) != 1 {
+		t.Errorf("expected the request not to be modified, got %+v", definition)
+	}
+}
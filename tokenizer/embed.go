@@ -0,0 +1,60 @@
+package tokenizer
+
+import (
+	"compress/gzip"
+	"embed"
+	"fmt"
+	"sync"
+)
+
+// ranksFS holds the .tiktoken files of the supported encodings, gzipped, as
+// published by OpenAI with tiktoken (https://github.com/openai/tiktoken, MIT
+// license).
+//
+//go:embed ranks/*.tiktoken.gz
+var ranksFS embed.FS
+
+type bundledEncoding struct {
+	once     sync.Once
+	encoding *Encoding
+	err      error
+}
+
+var bundledEncodings = map[string]*bundledEncoding{
+	Cl100kBase: {},
+	O200kBase:  {},
+}
+
+// GetEncoding returns the encoding named name, Cl100kBase or O200kBase, with
+// the ranks bundled with this package. The ranks are loaded on first use, and
+// the encoding is shared by every caller: it is safe for concurrent use.
+func GetEncoding(name string) (*Encoding, error) {
+	bundled, ok := bundledEncodings[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEncoding, name)
+	}
+	bundled.once.Do(func() {
+		bundled.encoding, bundled.err = loadBundledEncoding(name)
+	})
+	return bundled.encoding, bundled.err
+}
+
+// EncodingForModel returns the bundled encoding used by model.
+func EncodingForModel(model string) (*Encoding, error) {
+	return GetEncoding(EncodingNameForModel(model))
+}
+
+func loadBundledEncoding(name string) (*Encoding, error) {
+	f, err := ranksFS.Open("ranks/" + name + ".tiktoken.gz")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return LoadEncoding(name, r)
+}
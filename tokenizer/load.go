@@ -0,0 +1,119 @@
+package tokenizer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Names of the supported encodings.
+const (
+	Cl100kBase = "cl100k_base"
+	O200kBase  = "o200k_base"
+)
+
+// whitespace is the class of \s in the original patterns, which matches every
+// Unicode whitespace character unlike \s in RE2.
+const whitespace = `\t\n\v\f\r\x{85}\p{Z}`
+
+type encodingSpec struct {
+	pattern       *regexp.Regexp
+	specialTokens map[string]int
+}
+
+var encodingSpecs = map[string]encodingSpec{
+	Cl100kBase: {
+		pattern: regexp.MustCompile(strings.Join([]string{
+			`(?i:'s|'t|'re|'ve|'m|'ll|'d)`,
+			`[^\r\n\p{L}\p{N}]?\p{L}+`,
+			`\p{N}{1,3}`,
+			` ?[^` + whitespace + `\p{L}\p{N}]+[\r\n]*`,
+			`[` + whitespace + `]*[\r\n]+`,
+			`[` + whitespace + `]+`,
+		}, "|")),
+		specialTokens: map[string]int{
+			"<|endoftext|>":   100257,
+			"<|fim_prefix|>":  100258,
+			"<|fim_middle|>":  100259,
+			"<|fim_suffix|>":  100260,
+			"<|endofprompt|>": 100276,
+		},
+	},
+	O200kBase: {
+		pattern: regexp.MustCompile(strings.Join([]string{
+			`[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]*[\p{Ll}\p{Lm}\p{Lo}\p{M}]+(?i:'s|'t|'re|'ve|'m|'ll|'d)?`,
+			`[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]+[\p{Ll}\p{Lm}\p{Lo}\p{M}]*(?i:'s|'t|'re|'ve|'m|'ll|'d)?`,
+			`\p{N}{1,3}`,
+			` ?[^` + whitespace + `\p{L}\p{N}]+[\r\n/]*`,
+			`[` + whitespace + `]*[\r\n]+`,
+			`[` + whitespace + `]+`,
+		}, "|")),
+		specialTokens: map[string]int{
+			"<|endoftext|>":   199999,
+			"<|endofprompt|>": 200018,
+		},
+	},
+}
+
+// LoadRanks reads merge ranks in the .tiktoken format: one token per line,
+// base64 encoded, followed by a space and its rank.
+func LoadRanks(r io.Reader) (map[string]int, error) {
+	ranks := make(map[string]int)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := bytes.Fields(scanner.Bytes())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid ranks at line %d", line)
+		}
+		token, err := base64.StdEncoding.DecodeString(string(fields[0]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid token at line %d: %w", line, err)
+		}
+		rank, err := strconv.Atoi(string(fields[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid rank at line %d: %w", line, err)
+		}
+		ranks[string(token)] = rank
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return ranks, nil
+}
+
+// LoadEncoding creates the encoding named name with the ranks read from r in
+// the .tiktoken format.
+func LoadEncoding(name string, r io.Reader) (*Encoding, error) {
+	ranks, err := LoadRanks(r)
+	if err != nil {
+		return nil, err
+	}
+	return NewEncoding(name, ranks)
+}
+
+// EncodingNameForModel returns the name of the encoding used by model.
+// Models that are not known to use cl100k_base use o200k_base.
+func EncodingNameForModel(model string) string {
+	model = strings.TrimPrefix(model, "ft:")
+	for _, prefix := range []string{"gpt-4o", "chatgpt-4o", "gpt-4.1", "gpt-4.5"} {
+		if strings.HasPrefix(model, prefix) {
+			return O200kBase
+		}
+	}
+	for _, prefix := range []string{
+		"gpt-4", "gpt-3.5-turbo", "gpt-35-turbo", "text-embedding-", "davinci-002", "babbage-002",
+	} {
+		if strings.HasPrefix(model, prefix) {
+			return Cl100kBase
+		}
+	}
+	return O200kBase
+}
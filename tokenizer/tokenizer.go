@@ -0,0 +1,197 @@
+// Package tokenizer implements the byte pair encodings used by OpenAI models,
+// cl100k_base and o200k_base, to count tokens without calling the API.
+//
+// The merge ranks of both encodings are bundled with this package: GetEncoding
+// and EncodingForModel return ready to use encodings. LoadEncoding creates
+// them from other copies of the .tiktoken files published by OpenAI.
+package tokenizer
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	ErrUnknownEncoding = errors.New("unknown encoding")
+	ErrIncompleteRanks = errors.New("ranks do not contain every single byte")
+	ErrUnknownToken    = errors.New("unknown token")
+)
+
+// Encoding converts text to tokens and back.
+type Encoding struct {
+	name    string
+	pattern *regexp.Regexp
+	ranks   map[string]int
+	decoder map[int]string
+}
+
+// NewEncoding creates the encoding named name, Cl100kBase or O200kBase, with
+// the merge ranks of its tokens. ranks maps every token, as raw bytes, to its
+// rank, which is also its id.
+func NewEncoding(name string, ranks map[string]int) (*Encoding, error) {
+	spec, ok := encodingSpecs[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEncoding, name)
+	}
+	for b := 0; b < 256; b++ {
+		if _, ok := ranks[string([]byte{byte(b)})]; !ok {
+			return nil, fmt.Errorf("%w: byte %d is missing", ErrIncompleteRanks, b)
+		}
+	}
+
+	decoder := make(map[int]string, len(ranks)+len(spec.specialTokens))
+	for token, rank := range ranks {
+		decoder[rank] = token
+	}
+	for token, rank := range spec.specialTokens {
+		decoder[rank] = token
+	}
+	return &Encoding{
+		name:    name,
+		pattern: spec.pattern,
+		ranks:   ranks,
+		decoder: decoder,
+	}, nil
+}
+
+// Name returns the name of the encoding.
+func (e *Encoding) Name() string {
+	return e.name
+}
+
+// Encode returns the tokens of text. Special tokens such as <|endoftext|> are
+// encoded as plain text.
+func (e *Encoding) Encode(text string) []int {
+	var tokens []int
+	for _, piece := range e.split(text) {
+		if rank, ok := e.ranks[piece]; ok {
+			tokens = append(tokens, rank)
+			continue
+		}
+		tokens = append(tokens, e.bytePairEncode(piece)...)
+	}
+	return tokens
+}
+
+// Count returns the number of tokens of text.
+func (e *Encoding) Count(text string) int {
+	count := 0
+	for _, piece := range e.split(text) {
+		if _, ok := e.ranks[piece]; ok {
+			count++
+			continue
+		}
+		count += len(e.bytePairEncode(piece))
+	}
+	return count
+}
+
+// Decode returns the text of tokens.
+func (e *Encoding) Decode(tokens []int) (string, error) {
+	b := make([]byte, 0, len(tokens)*4)
+	for _, token := range tokens {
+		piece, ok := e.decoder[token]
+		if !ok {
+			return "", fmt.Errorf("%w: %d", ErrUnknownToken, token)
+		}
+		b = append(b, piece...)
+	}
+	return string(b), nil
+}
+
+// split splits text into the pieces that are encoded separately.
+//
+// The patterns of the encodings end with `\s+(?!\S)|\s+`, which RE2 cannot
+// express. They are compiled with `\s+` instead, and a run of whitespace
+// followed by a non whitespace character gives its last character back, as
+// the lookahead does.
+func (e *Encoding) split(text string) []string {
+	var pieces []string
+	for start := 0; start < len(text); {
+		loc := e.pattern.FindStringIndex(text[start:])
+		if loc == nil {
+			break
+		}
+		end := start + loc[1]
+		if match := text[start+loc[0] : end]; end < len(text) && isSpaceRun(match) {
+			if _, size := utf8.DecodeLastRuneInString(match); size < len(match) {
+				end -= size
+			}
+		}
+		pieces = append(pieces, text[start+loc[0]:end])
+		start = end
+	}
+	return pieces
+}
+
+// isSpaceRun reports whether s was matched by the trailing `\s+` of a pattern:
+// it only holds whitespace and does not end with a line break.
+func isSpaceRun(s string) bool {
+	last, _ := utf8.DecodeLastRuneInString(s)
+	if last == '\r' || last == '\n' {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// bytePairEncode merges the bytes of piece, lowest rank first, into tokens.
+func (e *Encoding) bytePairEncode(piece string) []int {
+	if len(piece) == 1 {
+		return []int{e.ranks[piece]}
+	}
+
+	type part struct {
+		start int
+		rank  int
+	}
+	parts := make([]part, len(piece)+1)
+	for i := range parts {
+		parts[i] = part{start: i, rank: math.MaxInt}
+	}
+	// rank returns the rank of the merge of parts i and i+1, with parts i+2
+	// removed when skip is set.
+	rank := func(i, skip int) int {
+		if i+2+skip >= len(parts) {
+			return math.MaxInt
+		}
+		if r, ok := e.ranks[piece[parts[i].start:parts[i+2+skip].start]]; ok {
+			return r
+		}
+		return math.MaxInt
+	}
+	for i := 0; i < len(parts)-2; i++ {
+		parts[i].rank = rank(i, 0)
+	}
+
+	for {
+		minIndex, minRank := 0, math.MaxInt
+		for i := 0; i < len(parts)-1; i++ {
+			if parts[i].rank < minRank {
+				minIndex, minRank = i, parts[i].rank
+			}
+		}
+		if minRank == math.MaxInt {
+			break
+		}
+		if minIndex > 0 {
+			parts[minIndex-1].rank = rank(minIndex-1, 1)
+		}
+		parts[minIndex].rank = rank(minIndex, 1)
+		parts = append(parts[:minIndex+1], parts[minIndex+2:]...)
+	}
+
+	tokens := make([]int, 0, len(parts)-1)
+	for i := 0; i < len(parts)-1; i++ {
+		tokens = append(tokens, e.ranks[piece[parts[i].start:parts[i+1].start]])
+	}
+	return tokens
+}
@@ -0,0 +1,32 @@
+package tokenizer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		encoding string
+		text     string
+		want     []string
+	}{
+		{Cl100kBase, "Hello world", []string{"Hello", " world"}},
+		{Cl100kBase, "I'm  here!\n\nOK", []string{"I", "'m", " ", " here", "!\n\n", "OK"}},
+		{Cl100kBase, "12345 ", []string{"123", "45", " "}},
+		{Cl100kBase, "a   ", []string{"a", "   "}},
+		{Cl100kBase, "a \n  b", []string{"a", " \n", " ", " b"}},
+		{Cl100kBase, "x　　y", []string{"x", "　", "　y"}},
+		{Cl100kBase, "don't HelloWorld", []string{"don", "'t", " HelloWorld"}},
+		{O200kBase, "don't HelloWorld", []string{"don't", " Hello", "World"}},
+		{O200kBase, "path/\nnext", []string{"path", "/\n", "next"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.encoding+" "+tc.text, func(t *testing.T) {
+			e := &Encoding{pattern: encodingSpecs[tc.encoding].pattern}
+			if got := e.split(tc.text); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
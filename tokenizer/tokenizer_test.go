@@ -0,0 +1,158 @@
+package tokenizer_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ibanyu/go-openai/tokenizer"
+)
+
+// testRanks returns ranks made of every single byte, ranked by value, and
+// the given merged tokens.
+func testRanks(merges ...string) string {
+	var b strings.Builder
+	for i := 0; i < 256; i++ {
+		fmt.Fprintf(&b, "%s %d\n", base64.StdEncoding.EncodeToString([]byte{byte(i)}), i)
+	}
+	for i, merge := range merges {
+		fmt.Fprintf(&b, "%s %d\n", base64.StdEncoding.EncodeToString([]byte(merge)), 256+i)
+	}
+	return b.String()
+}
+
+func TestEncoding(t *testing.T) {
+	encoding, err := tokenizer.LoadEncoding(tokenizer.Cl100kBase,
+		strings.NewReader(testRanks("cd", "ab", "bc", "abcd", " x")))
+	if err != nil {
+		t.Fatalf("LoadEncoding error: %v", err)
+	}
+	if encoding.Name() != tokenizer.Cl100kBase {
+		t.Errorf("unexpected name: %s", encoding.Name())
+	}
+
+	// cd merges first, then ab, then abcd; bc is never formed.
+	tokens := encoding.Encode("abcde x")
+	if want := []int{259, 'e', 260}; !reflect.DeepEqual(tokens, want) {
+		t.Errorf("Encode got %v, want %v", tokens, want)
+	}
+	if count := encoding.Count("abcde x"); count != 3 {
+		t.Errorf("Count got %d, want 3", count)
+	}
+
+	text, err := encoding.Decode(append(tokens, 100257))
+	if err != nil || text != "abcde x<|endoftext|>" {
+		t.Errorf("Decode got %q, %v", text, err)
+	}
+	if _, err = encoding.Decode([]int{99999}); !errors.Is(err, tokenizer.ErrUnknownToken) {
+		t.Errorf("expected ErrUnknownToken, got %v", err)
+	}
+
+	for _, text := range []string{"", "héllo, wörld! 👋", "\x80\xff invalid"} {
+		decoded, err := encoding.Decode(encoding.Encode(text))
+		if err != nil || decoded != text {
+			t.Errorf("round trip of %q got %q, %v", text, decoded, err)
+		}
+	}
+}
+
+func TestNewEncodingErrors(t *testing.T) {
+	ranks, err := tokenizer.LoadRanks(strings.NewReader(testRanks()))
+	if err != nil {
+		t.Fatalf("LoadRanks error: %v", err)
+	}
+	if _, err = tokenizer.NewEncoding("p50k_base", ranks); !errors.Is(err, tokenizer.ErrUnknownEncoding) {
+		t.Errorf("expected ErrUnknownEncoding, got %v", err)
+	}
+	delete(ranks, "a")
+	if _, err = tokenizer.NewEncoding(tokenizer.O200kBase, ranks); !errors.Is(err, tokenizer.ErrIncompleteRanks) {
+		t.Errorf("expected ErrIncompleteRanks, got %v", err)
+	}
+
+	for _, ranks := range []string{"YQ==", "!!! 1", "YQ== one"} {
+		if _, err = tokenizer.LoadRanks(strings.NewReader(ranks)); err == nil {
+			t.Errorf("expected an error for %q", ranks)
+		}
+	}
+}
+
+func TestEncodingNameForModel(t *testing.T) {
+	tests := map[string]string{
+		"gpt-4o-mini":                       tokenizer.O200kBase,
+		"gpt-4.1":                           tokenizer.O200kBase,
+		"o3-mini":                           tokenizer.O200kBase,
+		"ft:gpt-4o-2024-08-06:acme::abc123": tokenizer.O200kBase,
+		"gpt-4-turbo":                       tokenizer.Cl100kBase,
+		"gpt-3.5-turbo-0125":                tokenizer.Cl100kBase,
+		"text-embedding-3-small":            tokenizer.Cl100kBase,
+	}
+	for model, want := range tests {
+		if got := tokenizer.EncodingNameForModel(model); got != want {
+			t.Errorf("%s: got %s, want %s", model, got, want)
+		}
+	}
+}
+
+// TestGetEncoding compares the bundled encodings with the tokens of tiktoken.
+func TestGetEncoding(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		tokens []int
+	}{
+		{tokenizer.Cl100kBase, "hello world", []int{15339, 1917}},
+		{tokenizer.Cl100kBase, "Hello, world!", []int{9906, 11, 1917, 0}},
+		{tokenizer.Cl100kBase, "hello world!你好，世界！", []int{
+			15339, 1917, 0, 57668, 53901, 3922, 3574, 244, 98220, 6447,
+		}},
+		{tokenizer.O200kBase, "hello world", []int{24912, 2375}},
+		{tokenizer.O200kBase, "Hello, world!", []int{13225, 11, 2375, 0}},
+	}
+	for _, tc := range tests {
+		encoding, err := tokenizer.GetEncoding(tc.name)
+		if err != nil {
+			t.Fatalf("GetEncoding error: %v", err)
+		}
+		if tokens := encoding.Encode(tc.text); !reflect.DeepEqual(tokens, tc.tokens) {
+			t.Errorf("%s: Encode(%q) got %v, want %v", tc.name, tc.text, tokens, tc.tokens)
+		}
+		if text, err := encoding.Decode(tc.tokens); err != nil || text != tc.text {
+			t.Errorf("%s: Decode(%v) got %q, %v", tc.name, tc.tokens, text, err)
+		}
+	}
+
+	counts := []struct {
+		text          string
+		cl100k, o200k int
+	}{
+		{"hallo world!", 4, 4},
+		{"你好世界！", 6, 3},
+		{"こんにちは世界！", 5, 3},
+		{"안녕하세요 세계!", 10, 4},
+		{"Привет мир!", 6, 4},
+		{"¡Hola mundo!", 4, 4},
+		{"Bonjour le monde!", 4, 4},
+		{"Hej världen!", 7, 3},
+		{"Hallo verden!", 4, 3},
+	}
+	cl100k, _ := tokenizer.GetEncoding(tokenizer.Cl100kBase)
+	o200k, _ := tokenizer.GetEncoding(tokenizer.O200kBase)
+	for _, tc := range counts {
+		if count := cl100k.Count(tc.text); count != tc.cl100k {
+			t.Errorf("cl100k_base: Count(%q) got %d, want %d", tc.text, count, tc.cl100k)
+		}
+		if count := o200k.Count(tc.text); count != tc.o200k {
+			t.Errorf("o200k_base: Count(%q) got %d, want %d", tc.text, count, tc.o200k)
+		}
+	}
+
+	if _, err := tokenizer.GetEncoding("p50k_base"); !errors.Is(err, tokenizer.ErrUnknownEncoding) {
+		t.Errorf("expected ErrUnknownEncoding, got %v", err)
+	}
+	if encoding, err := tokenizer.EncodingForModel("gpt-4o"); err != nil || encoding != o200k {
+		t.Errorf("EncodingForModel got %p, %v", encoding, err)
+	}
+}
@@ -0,0 +1,53 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var ErrToolHandlerNotFound = errors.New("no handler registered for tool")
+
+// ToolErrorType classifies the failure of a tool call reported to the model.
+type ToolErrorType string
+
+const (
+	ToolErrorTypeUnknownFunction  ToolErrorType = "unknown_function"
+	ToolErrorTypeInvalidArguments ToolErrorType = "invalid_arguments"
+	ToolErrorTypeExecution        ToolErrorType = "execution_error"
+)
+
+// ToolCallError is the structured error sent to the model as the output of a
+// failed tool call, so that it can correct the call or recover.
+type ToolCallError struct {
+	Type    ToolErrorType `json:"type"`
+	Message string        `json:"error"`
+}
+
+// ToolHandlerFunc executes a function tool call. It receives the JSON
+// arguments generated by the model and returns the output sent back to it.
+type ToolHandlerFunc func(ctx context.Context, arguments string) (string, error)
+
+// ToolHandlers maps function names to the handlers executing them.
+type ToolHandlers map[string]ToolHandlerFunc
+
+// call runs the handler for a tool call. Failures are reported to the model
+// as a ToolCallError rather than returned, so that it can recover.
+func (h ToolHandlers) call(ctx context.Context, toolCall ToolCall) string {
+	handler, ok := h[toolCall.Function.Name]
+	if !ok {
+		err := fmt.Errorf("%w: %s", ErrToolHandlerNotFound, toolCall.Function.Name)
+		return toolErrorOutput(ToolErrorTypeUnknownFunction, err)
+	}
+	output, err := handler(ctx, toolCall.Function.Arguments)
+	if err != nil {
+		return toolErrorOutput(ToolErrorTypeExecution, err)
+	}
+	return output
+}
+
+func toolErrorOutput(errType ToolErrorType, err error) string {
+	output, _ := json.Marshal(ToolCallError{Type: errType, Message: err.Error()})
+	return string(output)
+}
@@ -0,0 +1,208 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+const defaultToolRunMaxIterations = 10
+
+var (
+	ErrToolRunMaxIterations = errors.New("tool run reached the maximum number of iterations")
+	ErrToolRunNoChoices     = errors.New("tool run received a response without choices")
+	ErrToolPanicked         = errors.New("tool panicked")
+)
+
+// ToolDispatcher executes the tool calls requested by a model.
+type ToolDispatcher interface {
+	// Dispatch runs a tool call and returns the tool message answering it.
+	Dispatch(ctx context.Context, toolCall ToolCall) ChatCompletionMessage
+}
+
+// Dispatch implements ToolDispatcher.
+func (h ToolHandlers) Dispatch(ctx context.Context, toolCall ToolCall) ChatCompletionMessage {
+	return ChatCompletionMessage{
+		Role:       ChatMessageRoleTool,
+		Content:    h.call(ctx, toolCall),
+		ToolCallID: toolCall.ID,
+	}
+}
+
+// ToolRunOptions configures RunTools.
+type ToolRunOptions struct {
+	// MaxIterations limits the number of chat completions. Defaults to 10.
+	MaxIterations int
+	// Stream requests every completion with CreateChatCompletionStream.
+	Stream bool
+	// OnChunk, when set, receives every chunk received in streaming mode.
+	OnChunk func(chunk ChatCompletionStreamResponse)
+}
+
+// ToolRunResult is the outcome of RunTools.
+type ToolRunResult struct {
+	// Messages is the full transcript: the request messages followed by every
+	// assistant and tool message of the run.
+	Messages []ChatCompletionMessage
+	// Response is the last chat completion response.
+	Response ChatCompletionResponse
+	// Usage is the sum of the usage of every completion. In streaming mode it
+	// is only reported when the request sets StreamOptions.IncludeUsage.
+	Usage Usage
+	// Iterations is the number of completions requested.
+	Iterations int
+}
+
+// RunTools requests chat completions until the model stops calling tools.
+// The tool calls of every response are executed concurrently by dispatcher,
+// and their results are sent back to the model in the next request. It
+// returns ErrToolRunMaxIterations, along with the transcript so far, when the
+// model still calls tools after MaxIterations completions.
+func (c *Client) RunTools(
+	ctx context.Context,
+	request ChatCompletionRequest,
+	dispatcher ToolDispatcher,
+	options ToolRunOptions,
+) (result ToolRunResult, err error) {
+	maxIterations := options.MaxIterations
+	if maxIterations <= 0 {
+		maxIterations = defaultToolRunMaxIterations
+	}
+
+	result.Messages = append(result.Messages, request.Messages...)
+	for result.Iterations < maxIterations {
+		request.Messages = result.Messages
+		result.Response, err = c.createToolRunCompletion(ctx, request, options)
+		result.Iterations++
+		if err != nil {
+			return
+		}
+		addUsage(&result.Usage, result.Response.Usage)
+		if len(result.Response.Choices) == 0 {
+			err = ErrToolRunNoChoices
+			return
+		}
+
+		message := toolRunAssistantMessage(result.Response.Choices[0].Message)
+		result.Messages = append(result.Messages, message)
+		if len(message.ToolCalls) == 0 {
+			return
+		}
+		result.Messages = append(result.Messages, dispatchToolCalls(ctx, dispatcher, message.ToolCalls)...)
+	}
+	err = ErrToolRunMaxIterations
+	return
+}
+
+func (c *Client) createToolRunCompletion(
+	ctx context.Context,
+	request ChatCompletionRequest,
+	options ToolRunOptions,
+) (ChatCompletionResponse, error) {
+	if !options.Stream {
+		return c.CreateChatCompletion(ctx, request)
+	}
+
+	stream, err := c.CreateChatCompletionStream(ctx, request)
+	if err != nil {
+		return ChatCompletionResponse{}, err
+	}
+	defer stream.Close()
+
+	accumulator := NewChatCompletionAccumulator()
+	for {
+		chunk, recvErr := stream.Recv()
+		if errors.Is(recvErr, io.EOF) {
+			return accumulator.Response(), nil
+		}
+		if recvErr != nil {
+			return accumulator.Response(), recvErr
+		}
+		if options.OnChunk != nil {
+			options.OnChunk(chunk)
+		}
+		accumulator.Add(chunk)
+	}
+}
+
+// toolRunAssistantMessage keeps the fields of a response message that are
+// sent back to the model, dropping reasoning content and extension fields.
+func toolRunAssistantMessage(message ChatCompletionMessage) ChatCompletionMessage {
+	assistant := ChatCompletionMessage{
+		Role:    ChatMessageRoleAssistant,
+		Content: message.Content,
+		Refusal: message.Refusal,
+	}
+	for _, toolCall := range message.ToolCalls {
+		assistant.ToolCalls = append(assistant.ToolCalls, ToolCall{
+			ID:   toolCall.ID,
+			Type: toolCall.Type,
+			Function: FunctionCall{
+				Name:      toolCall.Function.Name,
+				Arguments: toolCall.Function.Arguments,
+			},
+		})
+	}
+	return assistant
+}
+
+// dispatchToolCalls runs tool calls concurrently and returns their messages
+// in the order of the calls.
+func dispatchToolCalls(ctx context.Context, dispatcher ToolDispatcher, toolCalls []ToolCall) []ChatCompletionMessage {
+	messages := make([]ChatCompletionMessage, len(toolCalls))
+	var wg sync.WaitGroup
+	for i, toolCall := range toolCalls {
+		wg.Add(1)
+		go func(i int, toolCall ToolCall) {
+			defer wg.Done()
+			messages[i] = dispatchToolCall(ctx, dispatcher, toolCall)
+		}(i, toolCall)
+	}
+	wg.Wait()
+	return messages
+}
+
+// dispatchToolCall runs a tool call. A panic of the dispatcher is reported
+// to the model as the error of the call instead of crashing the process.
+func dispatchToolCall(
+	ctx context.Context,
+	dispatcher ToolDispatcher,
+	toolCall ToolCall,
+) (message ChatCompletionMessage) {
+	defer func() {
+		if r := recover(); r != nil {
+			message = ChatCompletionMessage{
+				Content: toolErrorOutput(ToolErrorTypeExecution, fmt.Errorf("%w: %v", ErrToolPanicked, r)),
+			}
+		}
+		if message.Role == "" {
+			message.Role = ChatMessageRoleTool
+		}
+		if message.ToolCallID == "" {
+			message.ToolCallID = toolCall.ID
+		}
+	}()
+	return dispatcher.Dispatch(ctx, toolCall)
+}
+
+func addUsage(total *Usage, usage Usage) {
+	total.PromptTokens += usage.PromptTokens
+	total.CompletionTokens += usage.CompletionTokens
+	total.TotalTokens += usage.TotalTokens
+	if usage.PromptTokensDetails != nil {
+		if total.PromptTokensDetails == nil {
+			total.PromptTokensDetails = &PromptTokensDetails{}
+		}
+		total.PromptTokensDetails.AudioTokens += usage.PromptTokensDetails.AudioTokens
+		total.PromptTokensDetails.CachedTokens += usage.PromptTokensDetails.CachedTokens
+	}
+	if usage.CompletionTokensDetails != nil {
+		if total.CompletionTokensDetails == nil {
+			total.CompletionTokensDetails = &CompletionTokensDetails{}
+		}
+		total.CompletionTokensDetails.AudioTokens += usage.CompletionTokensDetails.AudioTokens
+		total.CompletionTokensDetails.ReasoningTokens += usage.CompletionTokensDetails.ReasoningTokens
+	}
+}
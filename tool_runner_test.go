@@ -0,0 +1,179 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ibanyu/go-openai"
+	"github.com/ibanyu/go-openai/internal/test/checks"
+)
+
+const toolRunToolCallsResponse = `{"id":"1","choices":[{"index":0,"finish_reason":"tool_calls","message":{` +
+	`"role":"assistant","reasoning_content":"think","tool_calls":[` +
+	`{"id":"call_1","type":"function","function":{"name":"wait","arguments":"{}"}},` +
+	`{"id":"call_2","type":"function","function":{"name":"wait","arguments":"{}"}}]}}],` +
+	`"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`
+
+func TestRunTools(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	requests := 0
+	var lastRequest openai.ChatCompletionRequest
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&lastRequest), "decode request error")
+		if requests == 1 {
+			fmt.Fprint(w, toolRunToolCallsResponse)
+			return
+		}
+		fmt.Fprint(w, `{"id":"2","choices":[{"index":0,"finish_reason":"stop",`+
+			`"message":{"role":"assistant","content":"done"}}],`+
+			`"usage":{"prompt_tokens":20,"completion_tokens":1,"total_tokens":21}}`)
+	})
+
+	var running, maxRunning int32
+	handlers := openai.ToolHandlers{
+		"wait": func(context.Context, string) (string, error) {
+			current := atomic.AddInt32(&running, 1)
+			for {
+				seen := atomic.LoadInt32(&maxRunning)
+				if current <= seen || atomic.CompareAndSwapInt32(&maxRunning, seen, current) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return "ok", nil
+		},
+	}
+
+	result, err := client.RunTools(context.Background(), openai.ChatCompletionRequest{
+		Model:    openai.GPT4o,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello!"}},
+	}, handlers, openai.ToolRunOptions{})
+	checks.NoError(t, err, "RunTools error")
+
+	if result.Iterations != 2 || len(result.Messages) != 5 {
+		t.Fatalf("unexpected result after %d iterations: %+v", result.Iterations, result.Messages)
+	}
+	if result.Messages[4].Content != "done" || result.Response.ID != "2" {
+		t.Errorf("unexpected final message: %+v", result.Messages[4])
+	}
+	if result.Usage.TotalTokens != 36 || result.Usage.PromptTokens != 30 {
+		t.Errorf("unexpected usage: %+v", result.Usage)
+	}
+	if maxRunning != 2 {
+		t.Errorf("expected tool calls to run concurrently, max running was %d", maxRunning)
+	}
+
+	sent := lastRequest.Messages
+	if len(sent) != 4 || sent[1].ReasoningContent != "" || len(sent[1].ToolCalls) != 2 {
+		t.Fatalf("unexpected messages sent back: %+v", sent)
+	}
+	if sent[2].Role != openai.ChatMessageRoleTool || sent[2].ToolCallID != "call_1" || sent[2].Content != "ok" ||
+		sent[3].ToolCallID != "call_2" {
+		t.Errorf("unexpected tool messages: %+v", sent[2:])
+	}
+}
+
+func TestRunToolsStream(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	requests := 0
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "text/event-stream")
+		if requests == 1 {
+			fmt.Fprint(w, `data: {"id":"1","choices":[{"index":0,"delta":{"role":"assistant","tool_calls":[`+
+				`{"index":0,"id":"call_1","type":"function","function":{"name":"echo","arguments":"{\"v\":"}}]}}]}`+"\n\n")
+			fmt.Fprint(w, `data: {"id":"1","choices":[{"index":0,"delta":{"tool_calls":[`+
+				`{"index":0,"function":{"arguments":"1}"}}]},"finish_reason":"tool_calls"}]}`+"\n\n")
+		} else {
+			fmt.Fprint(w, `data: {"id":"2","choices":[{"index":0,"delta":{"content":"1"},"finish_reason":"stop"}]}`+"\n\n")
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	})
+
+	var arguments string
+	handlers := openai.ToolHandlers{
+		"echo": func(_ context.Context, args string) (string, error) {
+			arguments = args
+			return args, nil
+		},
+	}
+	chunks := 0
+	result, err := client.RunTools(context.Background(), openai.ChatCompletionRequest{
+		Model:    openai.GPT4o,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello!"}},
+	}, handlers, openai.ToolRunOptions{
+		Stream:  true,
+		OnChunk: func(openai.ChatCompletionStreamResponse) { chunks++ },
+	})
+	checks.NoError(t, err, "RunTools error")
+
+	if arguments != `{"v":1}` || chunks != 3 {
+		t.Errorf("unexpected arguments %q after %d chunks", arguments, chunks)
+	}
+	if len(result.Messages) != 4 || result.Messages[3].Content != "1" {
+		t.Errorf("unexpected transcript: %+v", result.Messages)
+	}
+}
+
+func TestRunToolsMaxIterations(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, toolRunToolCallsResponse)
+	})
+
+	result, err := client.RunTools(context.Background(), openai.ChatCompletionRequest{
+		Model:    openai.GPT4o,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello!"}},
+	}, openai.ToolHandlers{}, openai.ToolRunOptions{MaxIterations: 2})
+	checks.ErrorIs(t, err, openai.ErrToolRunMaxIterations, "expected ErrToolRunMaxIterations")
+	if result.Iterations != 2 || len(result.Messages) != 7 {
+		t.Errorf("unexpected result: %d iterations, %d messages", result.Iterations, len(result.Messages))
+	}
+}
+
+func TestRunToolsRecoversPanics(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	var lastRequest openai.ChatCompletionRequest
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&lastRequest), "decode request error")
+		if len(lastRequest.Messages) == 1 {
+			fmt.Fprint(w, toolRunToolCallsResponse)
+			return
+		}
+		fmt.Fprint(w, `{"id":"2","choices":[{"index":0,"finish_reason":"stop",`+
+			`"message":{"role":"assistant","content":"done"}}]}`)
+	})
+
+	handlers := openai.ToolHandlers{
+		"wait": func(context.Context, string) (string, error) {
+			panic("out of range")
+		},
+	}
+	_, err := client.RunTools(context.Background(), openai.ChatCompletionRequest{
+		Model:    openai.GPT4o,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello!"}},
+	}, handlers, openai.ToolRunOptions{})
+	checks.NoError(t, err, "RunTools error")
+
+	want := `{"type":"execution_error","error":"tool panicked: out of range"}`
+	for _, message := range lastRequest.Messages[2:] {
+		if message.Role != openai.ChatMessageRoleTool || message.ToolCallID == "" || message.Content != want {
+			t.Errorf("unexpected tool message: %+v", message)
+		}
+	}
+}